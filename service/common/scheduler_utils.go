@@ -9,6 +9,7 @@ const (
 	SchedulingFrequencyEvery = "every"
 	SchedulingFrequencyAt    = "at"
 	SchedulingFrequencyCron  = "cron"
+	SchedulingFrequencyRRule = "rrule"
 	SchedulingFrequencyNow   = "now"
 
 	// Special device ID used to send out broadcast tasks/messages over
@@ -18,6 +19,10 @@ const (
 
 	SchedulerRequestSourceEvent = "event"
 	SchedulerRequestSourceRest  = "rest"
+
+	// Message type reserved by the scheduler for messages asking the device to
+	// cancel the task referenced by the task ID in the message.
+	CancelTaskMessageType = "SCHED.CANCEL"
 )
 
 // SchedulingUnit - defines the frequency with which tasks are scheduled.
@@ -36,8 +41,13 @@ const (
 	Months
 	Duration
 	Crontab
+	RRule
 )
 
+// DispatchFailureHandlerFunc - function to process tasks that could not be
+// delivered to the device by the dispatch queue watcher.
+type DispatchFailureHandlerFunc func(message *pb.ServiceMessage) error
+
 // InputEventHandlerFunc - function to process task requests received on the
 // scheduler input queue.
 type InputEventHandlerFunc func(request *pb.CreateScheduledTaskRequest,
@@ -68,6 +68,53 @@ type DatabaseConfig struct {
 	Password string
 }
 
+// Policy used to retry tasks that could not be delivered to the device or for
+// which the device reported a failure.
+type RetryPolicy struct {
+	// The maximum number of attempts to execute a task, including the first
+	// attempt. A value of 1 disables retries.
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// The delay before the first retry.
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+
+	// The maximum delay between retries.
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+
+	// The factor by which the delay is multiplied after each retry.
+	BackoffMultiplier float64 `yaml:"backoff_multiplier"`
+
+	// The task statuses for which tasks are retried.
+	RetryableStatuses []string `yaml:"retryable_statuses"`
+}
+
+// Scheduler engine configuration settings.
+type SchedulerConfig struct {
+	// How far ahead of the current time to look for scheduled runs that are
+	// due. Runs scheduled within this window are dispatched with the current
+	// pass of the scheduler daemon.
+	Lookahead time.Duration `yaml:"lookahead"`
+
+	// The interval between passes of the scheduler daemon. Runs due before the
+	// next pass are held in memory and dispatched at their exact time.
+	ExecutionQuantum time.Duration `yaml:"execution_quantum"`
+
+	// The number of hours of run partitions prior to the current hour that
+	// are checked for runs left over from an outage when the scheduler starts
+	// up.
+	CatchupHours int `yaml:"catchup_hours"`
+
+	// The duration of the lease claimed by a scheduler instance to dispatch
+	// scheduled runs. When running multiple scheduler instances, only the
+	// instance holding the lease dispatches scheduled runs. The lease is taken
+	// over by another instance if it is not renewed within this duration.
+	LeaseDuration time.Duration `yaml:"lease_duration"`
+
+	// The retry policy used for tasks requested by services that do not have
+	// a retry policy configured.
+	RetryPolicy RetryPolicy `yaml:"retry_policy"`
+}
+
 // Queue manager configuration settings.
 type QueueMgrConfig struct {
 	Endpoint string `yaml:"endpoint"`
@@ -164,6 +211,9 @@ type Config struct {
 	// Database configuration settings.
 	DatabaseConfig `yaml:"database"`
 
+	// Scheduler engine configuration settings.
+	SchedulerConfig `yaml:"scheduler"`
+
 	// Queue manager configuration settings.
 	QueueMgrConfig `yaml:"queuemgr"`
 
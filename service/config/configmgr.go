@@ -94,6 +94,11 @@ func (c *ConfigMgr) GetDatabaseConfig() *DatabaseConfig {
 	return &c.config.DatabaseConfig
 }
 
+// Return the scheduler engine configuration settings.
+func (c *ConfigMgr) GetSchedulerConfig() *SchedulerConfig {
+	return &c.config.SchedulerConfig
+}
+
 // Return the queue manager configuration settings.
 func (c *ConfigMgr) GetQueueMgrConfig() *QueueMgrConfig {
 	return &c.config.QueueMgrConfig
@@ -153,6 +158,12 @@ func (c *ConfigMgr) Display() {
 		zap.String(" - Keyspace provider", c.config.DatabaseConfig.DatabaseType),
 		zap.Strings(" - Hosts", c.config.DatabaseConfig.DatabaseHosts),
 	)
+	schedLogger.Info("Scheduler settings",
+		zap.Duration(" - Lookahead", c.config.SchedulerConfig.Lookahead),
+		zap.Duration(" - Execution quantum", c.config.SchedulerConfig.ExecutionQuantum),
+		zap.Int(" - Catch-up hours", c.config.SchedulerConfig.CatchupHours),
+		zap.Duration(" - Lease duration", c.config.SchedulerConfig.LeaseDuration),
+	)
 	schedLogger.Info("MQTT settings",
 		zap.Strings(" - Broker hosts", c.config.MqttConfig.MqttBrokerHosts),
 		zap.Uint16(" - Keep alive", c.config.MqttConfig.KeepAlive),
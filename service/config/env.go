@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -33,6 +34,12 @@ func (c *ConfigMgr) loadEnvironmentVariableOverrides() {
 		"SCHEDULER_DB_PASSWORD":        {isSecret: true, value: &c.config.DatabaseConfig.Password},
 		"SCHEDULER_DB_SCHEMA_LOCATION": {value: &c.config.DatabaseConfig.SchemaMigrationScripts},
 
+		// Scheduler engine configuration settings
+		"SCHEDULER_LOOKAHEAD":         {value: &c.config.SchedulerConfig.Lookahead},
+		"SCHEDULER_EXECUTION_QUANTUM": {value: &c.config.SchedulerConfig.ExecutionQuantum},
+		"SCHEDULER_CATCHUP_HOURS":     {value: &c.config.SchedulerConfig.CatchupHours},
+		"SCHEDULER_LEASE_DURATION":    {value: &c.config.SchedulerConfig.LeaseDuration},
+
 		// Notification configuration settings
 		"SCHEDULER_QUEUE_ENDPOINT":       {value: &c.config.QueueMgrConfig.Endpoint},
 		"SCHEDULER_INPUT_QUEUE_NAME":     {value: &c.config.QueueMgrConfig.InputQueueName},
@@ -95,6 +102,14 @@ func replaceConfigValue(envValue string, t *envSetting) {
 		} else {
 			*t.value.(*int) = i
 		}
+	case *time.Duration:
+		d, err := time.ParseDuration(envValue)
+		if err != nil {
+			schedLogger.Error("Bad duration value in env",
+				zap.Error(err))
+		} else {
+			*t.value.(*time.Duration) = d
+		}
 	default:
 		schedLogger.Error("There was a bad type map in env override",
 			zap.String("value", envValue))
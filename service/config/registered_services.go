@@ -25,6 +25,10 @@ type ServiceRegistration struct {
 	// Map of MQTT topics that the service is interested in and corresponding
 	// SQS input topics on which it would like to receive these MQTT messages.
 	Topics map[string]string `yaml:"topics"`
+
+	// The retry policy used for tasks requested by the service, unless a retry
+	// policy is specified when the task is requested.
+	RetryPolicy *RetryPolicy `yaml:"retry_policy"`
 }
 
 // Load configuration information for registered services from the YAML configuration
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Acquire or renew the specified lease on behalf of the specified owner for the
+// specified duration. Leases that have expired are taken over from their
+// previous owner. Lightweight transactions are used to ensure that a lease is
+// held by at most one owner at a time. Returns the lease and whether it is now
+// held by the specified owner.
+func AcquireLease(leaseName string, owner string,
+	duration time.Duration) (*Lease, bool, error) {
+	if leaseName == "" || owner == "" || duration <= 0 {
+		return nil, false, ErrInvalidRequest
+	}
+
+	now := time.Now()
+	lease := &Lease{
+		Name:      leaseName,
+		Owner:     owner,
+		ExpiresAt: now.Add(duration),
+	}
+	existing := map[string]interface{}{}
+
+	// Claim the lease if nobody has claimed it before.
+	gSessionMutex.RLock()
+	applied, err := gSession.Session.Query(`INSERT INTO scheduler_leases (lease_name, owner, expires_at) VALUES (?, ?, ?) IF NOT EXISTS`,
+		lease.Name, lease.Owner, lease.ExpiresAt).MapScanCAS(existing)
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to claim the lease in the scheduler database!",
+			zap.String("Lease:", leaseName),
+			zap.Error(err),
+		)
+		return nil, false, err
+	}
+	if applied {
+		return lease, true, nil
+	}
+
+	currentOwner, _ := existing["owner"].(string)
+	currentExpiry, _ := existing["expires_at"].(time.Time)
+
+	switch {
+	case currentOwner == owner:
+		// Renew the lease held by the owner.
+		gSessionMutex.RLock()
+		applied, err = gSession.Session.Query(`UPDATE scheduler_leases SET expires_at=? WHERE lease_name=? IF owner=?`,
+			lease.ExpiresAt, leaseName, owner).MapScanCAS(existing)
+		gSessionMutex.RUnlock()
+
+	case currentExpiry.Before(now):
+		// Take over the lease that has expired, provided that it has not
+		// been renewed or taken over by another owner in the meantime.
+		gSessionMutex.RLock()
+		applied, err = gSession.Session.Query(`UPDATE scheduler_leases SET owner=?, expires_at=? WHERE lease_name=? IF expires_at=?`,
+			owner, lease.ExpiresAt, leaseName, currentExpiry).MapScanCAS(existing)
+		gSessionMutex.RUnlock()
+
+	default:
+		// The lease is held by another owner.
+		return &Lease{
+			Name:      leaseName,
+			Owner:     currentOwner,
+			ExpiresAt: currentExpiry,
+		}, false, nil
+	}
+
+	if err != nil {
+		schedLogger.Error("Failed to renew the lease in the scheduler database!",
+			zap.String("Lease:", leaseName),
+			zap.Error(err),
+		)
+		return nil, false, err
+	}
+	return lease, applied, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+// Cancel the specified task. The task is marked as cancelled provided that its
+// status has not changed since it was read, otherwise ErrTaskStatusChanged is
+// returned. The current run of the task is then marked as cancelled and its
+// scheduled run and any pending retry are removed within a single logged batch,
+// so that the task is not dispatched again. The status of the task in its
+// consignment is updated afterwards. Unlike removed tasks, cancelled tasks
+// remain in the scheduler database.
+func CancelTask(task *Task) error {
+	endTime := time.Now()
+
+	err := updateTaskIfStatus(task.DeviceID, task.TaskID,
+		[]string{task.Status},
+		"status=?, end_time=?, next_run=null, retry_run=null",
+		TaskStatusCancelled.String(), endTime)
+	if err == nil {
+		gSessionMutex.RLock()
+		batch := gSession.Session.NewBatch(gocql.LoggedBatch)
+		if !task.CurrentRun.IsZero() {
+			batch.Query(`UPDATE task_runs SET status=?, end_time=? WHERE task_id=? AND run_time=?`,
+				TaskStatusCancelled.String(), endTime, task.TaskID,
+				task.CurrentRun)
+		}
+		removeScheduledRunsForTask(batch, task)
+		if batch.Size() > 0 {
+			err = gSession.Session.ExecuteBatch(batch)
+		}
+		gSessionMutex.RUnlock()
+	}
+	if err != nil {
+		schedLogger.Error("Failed to cancel the task!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.String("Device ID:", task.DeviceID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	task.Status = TaskStatusCancelled.String()
+	task.EndTime = endTime
+	task.NextRun = time.Time{}
+	task.RetryRun = time.Time{}
+
+	return UpdateConsignmentTaskStatus(task.TenantID, task.ConsignmentID,
+		task.TaskID.String(), TaskStatusCancelled)
+}
@@ -0,0 +1,38 @@
+package db
+
+import "time"
+
+// Represents a summary of the progress of the tasks in a consignment.
+type ConsignmentSummary struct {
+	// The consignment ID assigned by the service which requested the tasks.
+	ConsignmentID string `json:"consignment_id"`
+
+	// The tenant ID to which the consignment belongs.
+	TenantID string `json:"tenant_id"`
+
+	// The total number of tasks in the consignment.
+	TaskCount int64 `json:"task_count"`
+
+	// The number of tasks in the consignment with each status.
+	StatusCounts map[string]int64 `json:"status_counts"`
+
+	// The time at which the first task in the consignment was created.
+	FirstActivity time.Time `json:"first_activity"`
+
+	// The time at which a task in the consignment was most recently created or
+	// changed status.
+	LastActivity time.Time `json:"last_activity"`
+
+	// The percentage of tasks in the consignment which have finished, whether
+	// successfully or not.
+	CompletionPercentage float64 `json:"completion_percentage"`
+}
+
+// Task statuses which count towards the completion of a consignment.
+var finishedTaskStatuses = []TaskStatus{
+	TaskStatusCompleted,
+	TaskStatusFailed,
+	TaskStatusTimedOut,
+	TaskStatusCancelled,
+	TaskStatusScheduleExhausted,
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"github.com/gocql/gocql"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -37,24 +38,81 @@ func (c *Consignment) CreateConsignment() error {
 		)
 		return err
 	}
-	return nil
+
+	return updateConsignmentSummary(c.TenantID, c.ConsignmentID, "", c.Status)
 }
 
+// Update the status of the task in the consignment, along with the per-status
+// task counts for the consignment. The status is updated conditionally on the
+// previous status, so that concurrent updates do not skew the counts.
 func UpdateConsignmentTaskStatus(tenantID string, consignmentID string, taskID string,
 	status TaskStatus) error {
+	prevStatus, err := getConsignmentTaskStatus(tenantID, consignmentID, taskID)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for prevStatus != status.String() {
+		previous := map[string]interface{}{}
+
+		gSessionMutex.RLock()
+		applied, err := gSession.Session.Query(`UPDATE consignments SET status=? WHERE tenant_id=? AND consignment_id=? AND task_id=? IF status=?`,
+			status.String(), tenantID, consignmentID, taskID,
+			prevStatus).MapScanCAS(previous)
+		gSessionMutex.RUnlock()
+		if err != nil {
+			schedLogger.Error("Failed to update the task status!",
+				zap.String("Task ID:", taskID),
+				zap.String("Tenant ID:", tenantID),
+				zap.String("Consignment ID:", consignmentID),
+				zap.Error(err),
+			)
+			return err
+		}
+		if applied {
+			break
+		}
+
+		// The status was changed concurrently, or the task was removed from
+		// the consignment.
+		currentStatus, ok := previous["status"].(string)
+		if !ok {
+			return nil
+		}
+		if currentStatus == status.String() {
+			return nil
+		}
+		prevStatus = currentStatus
+	}
+
+	return updateConsignmentSummary(tenantID, consignmentID, prevStatus,
+		status.String())
+}
+
+// Get the status of the specified task in the consignment.
+func getConsignmentTaskStatus(tenantID string, consignmentID string,
+	taskID string) (string, error) {
+	var status string
+
 	gSessionMutex.RLock()
-	err := gSession.Session.Query(`UPDATE consignments SET status=? WHERE tenant_id=? AND consignment_id=? AND task_id=?`,
-		status.String(), tenantID, consignmentID, taskID).Exec()
+	err := gSession.Session.Query(`SELECT status FROM consignments WHERE tenant_id=? AND consignment_id=? AND task_id=?`,
+		tenantID, consignmentID, taskID).Scan(&status)
 	gSessionMutex.RUnlock()
+	if err == gocql.ErrNotFound {
+		return "", ErrNotFound
+	}
 	if err != nil {
-		schedLogger.Error("Failed to update the task status!",
+		schedLogger.Error("Failed to query the status of the task in the consignment!",
 			zap.String("Task ID:", taskID),
 			zap.String("Tenant ID:", tenantID),
 			zap.String("Consignment ID:", consignmentID),
 			zap.Error(err),
 		)
-		return err
+		return "", err
 	}
 
-	return nil
+	return status, nil
 }
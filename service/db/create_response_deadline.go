@@ -0,0 +1,27 @@
+package db
+
+import (
+	"go.uber.org/zap"
+)
+
+func (d *ResponseDeadline) CreateResponseDeadline() error {
+	d.DeadlinePartition = GetRunPartition(d.Deadline)
+	d.Shard = GetRunShard(d.TaskID)
+
+	// Create a new response deadline for the task in the scheduler database.
+	gSessionMutex.RLock()
+	err := gSession.Query(responseDeadlinesStatements.insert.statement,
+		responseDeadlinesStatements.insert.names).
+		BindStruct(d).
+		ExecRelease()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to add response deadline to the scheduler database!",
+			zap.String("Task ID:", d.TaskID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
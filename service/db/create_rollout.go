@@ -0,0 +1,73 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+const (
+	// Number of devices of a rollout recorded in a single batch.
+	rolloutDevicesPerBatch = 100
+)
+
+// Add the rollout to the scheduler database, along with the devices in each of
+// its later stages, indexed from 1. The rollout is added in the starting
+// status, and only one rollout may be added for a consignment. Returns
+// ErrDuplicateEntry if the consignment already has a rollout. If the devices
+// cannot be recorded, the rollout is removed again.
+func (r *Rollout) CreateRollout(stageDevices [][]string) error {
+	r.Status = RolloutStatusStarting
+	r.CreateTime = time.Now()
+
+	gSessionMutex.RLock()
+	applied, err := gSession.Session.Query(`INSERT INTO rollouts (tenant_id, consignment_id, request, stage, status, next_stage_at, baseline_failures, baseline_responses, stage_tasks, stage_responses, create_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) IF NOT EXISTS`,
+		r.TenantID, r.ConsignmentID, r.Request, r.Stage, r.Status,
+		r.NextStageAt, r.BaselineFailures, r.BaselineResponses, r.StageTasks,
+		r.StageResponses, r.CreateTime).MapScanCAS(map[string]interface{}{})
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to add the rollout to the scheduler database!",
+			zap.String("Tenant ID:", r.TenantID),
+			zap.String("Consignment ID:", r.ConsignmentID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if !applied {
+		return ErrDuplicateEntry
+	}
+
+	// The devices of the rollout are all stored in the same partition, so
+	// they are recorded in unlogged batches.
+	for stage, devices := range stageDevices {
+		for start := 0; start < len(devices); start += rolloutDevicesPerBatch {
+			end := start + rolloutDevicesPerBatch
+			if end > len(devices) {
+				end = len(devices)
+			}
+
+			gSessionMutex.RLock()
+			batch := gSession.Session.NewBatch(gocql.UnloggedBatch)
+			for _, deviceID := range devices[start:end] {
+				batch.Query(`INSERT INTO rollout_devices (tenant_id, consignment_id, stage, device_id) VALUES (?, ?, ?, ?)`,
+					r.TenantID, r.ConsignmentID, stage+1, deviceID)
+			}
+			err = gSession.Session.ExecuteBatch(batch)
+			gSessionMutex.RUnlock()
+			if err != nil {
+				schedLogger.Error("Failed to add the devices of the rollout to the scheduler database!",
+					zap.String("Tenant ID:", r.TenantID),
+					zap.String("Consignment ID:", r.ConsignmentID),
+					zap.Int("Stage:", stage+1),
+					zap.Error(err),
+				)
+				_ = r.RemoveRollout()
+				return err
+			}
+		}
+	}
+
+	return nil
+}
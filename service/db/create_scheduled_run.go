@@ -1,29 +1,56 @@
 package db
 
 import (
+	"hash/fnv"
 	"time"
 
+	"github.com/gocql/gocql"
 	"go.uber.org/zap"
 )
 
 const (
-	scheduledRunPartitionKeyDateFormat = "2006-Jan-02"
+	scheduledRunPartitionKeyDateFormat = "2006-Jan-02-15"
+
+	// Scheduled runs are partitioned into time buckets of this duration.
+	RunPartitionPeriod = time.Hour
+
+	// Number of shards into which each run partition is divided. Runs are
+	// assigned to shards by their task ID, so this is part of the schema and
+	// must not be changed once scheduled runs have been stored.
+	RunPartitionShards = 16
 )
 
+// Return the run partition in which runs scheduled at the specified instant are
+// stored. Partitions are always computed in UTC.
 func GetRunPartition(instant time.Time) string {
-	return instant.Format(scheduledRunPartitionKeyDateFormat)
+	return instant.UTC().Format(scheduledRunPartitionKeyDateFormat)
+}
+
+// Return the shard within a run partition to which runs of the specified task
+// are assigned.
+func GetRunShard(taskID gocql.UUID) int {
+	h := fnv.New32a()
+	_, _ = h.Write(taskID.Bytes())
+	return int(h.Sum32() % RunPartitionShards)
 }
 
+// Create the scheduled run in the scheduler database. The time of the run is
+// then recorded with the task, so that the run can be located when the task is
+// removed. Returns ErrNotFound if the task was removed concurrently, in which
+// case the run is removed again.
 func (s *ScheduledRun) CreateScheduledRun() error {
 	s.RunPartition = GetRunPartition(s.NextRun)
+	s.Shard = GetRunShard(s.TaskID)
 
 	// Create a new scheduled run for the task in the scheduler database.
 	gSessionMutex.RLock()
-	err := gSession.Query(scheduledRunsStatements.insert.statement,
-		scheduledRunsStatements.insert.names).
-		BindStruct(s).
-		ExecRelease()
+	err := gSession.Session.Query(`INSERT INTO scheduled_run_buckets (run_partition, shard, next_run, task_id, last_run, device_id, is_retry) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		s.RunPartition, s.Shard, s.NextRun, s.TaskID, s.LastRun, s.DeviceID,
+		s.IsRetry).Exec()
 	gSessionMutex.RUnlock()
+	if err == nil {
+		err = s.recordWithTask()
+	}
 	if err != nil {
 		schedLogger.Error("Failed to add scheduled run to the scheduler database!",
 			zap.String("Task ID:", s.TaskID.String()),
@@ -37,3 +64,25 @@ func (s *ScheduledRun) CreateScheduledRun() error {
 	)
 	return nil
 }
+
+// Record the time of the scheduled run with its task. The update is
+// conditional on the task existing, so that a task removed concurrently is not
+// recreated. If the task no longer exists, the scheduled run is removed and
+// ErrNotFound is returned.
+func (s *ScheduledRun) recordWithTask() error {
+	assignment := "next_run=?"
+	if s.IsRetry {
+		assignment = "retry_run=?"
+	}
+
+	applied, _, err := updateTaskIf(s.DeviceID, s.TaskID, assignment,
+		[]interface{}{s.NextRun}, "EXISTS")
+	if err != nil {
+		return err
+	}
+	if !applied {
+		_ = s.deleteRunEntry()
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Record the start of a new run of the task, scheduled at the specified time.
+// The run is recorded as the current run of the task so that the dispatch of
+// the task and the response from the device are recorded against it, and is
+// counted towards the maximum number of runs of the task.
+func (t *Task) CreateTaskRun(runTime time.Time) error {
+	return t.CreateMissedTaskRun(runTime, "")
+}
+
+// Record the start of a new run of the task as for CreateTaskRun, along with
+// the misfire decision taken if the run was missed. Recording a run is keyed on
+// its run time, so that a run whose dispatch is attempted again is neither
+// recorded nor counted twice. A new run of the task is subject to its retry
+// policy afresh, so the number of times the task has been retried is reset.
+// Runs are only recorded for tasks which may be run, otherwise an error
+// describing the status of the task is returned.
+func (t *Task) CreateMissedTaskRun(runTime time.Time,
+	misfireDecision string) error {
+	applied, previous, err := updateTaskIf(t.DeviceID, t.TaskID,
+		"current_run=?, run_count=?, retry_count=0",
+		[]interface{}{runTime, t.RunCount + 1},
+		"current_run!=? AND status IN ?", runTime, runTaskStatuses)
+	if err == nil && !applied {
+		currentRun, _ := previous["current_run"].(time.Time)
+		if !currentRun.Equal(runTime) {
+			return taskStatusError(previous["status"])
+		}
+	}
+
+	if err == nil {
+		gSessionMutex.RLock()
+		if misfireDecision == "" {
+			err = gSession.Session.Query(`INSERT INTO task_runs (task_id, run_time, device_id, status) VALUES (?, ?, ?, ?)`,
+				t.TaskID, runTime, t.DeviceID, TaskStatusQueued.String()).Exec()
+		} else {
+			err = gSession.Session.Query(`INSERT INTO task_runs (task_id, run_time, device_id, status, misfire_decision) VALUES (?, ?, ?, ?, ?)`,
+				t.TaskID, runTime, t.DeviceID, TaskStatusQueued.String(),
+				misfireDecision).Exec()
+		}
+		gSessionMutex.RUnlock()
+	}
+	if err != nil {
+		schedLogger.Error("Failed to add the task run to the scheduler database!",
+			zap.String("Task ID:", t.TaskID.String()),
+			zap.Time("Run Time:", runTime),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// The run count read with the task already includes a run which was
+	// recorded before.
+	t.CurrentRun = runTime
+	t.RetryCount = 0
+	if applied {
+		t.RunCount++
+	}
+	return nil
+}
+
+// Record a run of the task which was missed and skipped as per the misfire
+// policy of the task. Skipped runs are not dispatched, so they are neither
+// recorded as the current run of the task nor counted towards the maximum
+// number of runs of the task.
+func (t *Task) CreateSkippedTaskRun(runTime time.Time) error {
+	gSessionMutex.RLock()
+	err := gSession.Query(`INSERT INTO task_runs (task_id, run_time, device_id, status, misfire_decision, end_time) VALUES (?, ?, ?, ?, ?, ?)`,
+		nil).Bind(t.TaskID, runTime, t.DeviceID, taskRunStatusSkipped,
+		MisfireDecisionSkipped, time.Now()).ExecRelease()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to add the skipped task run to the scheduler database!",
+			zap.String("Task ID:", t.TaskID.String()),
+			zap.Time("Run Time:", runTime),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
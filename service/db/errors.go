@@ -10,4 +10,9 @@ var (
 	ErrNotAllowed          = errors.New("the requested operation is not allowed")
 	ErrInvalidRequest      = errors.New("the request contained one or more invalid parameters")
 	ErrInternalError       = errors.New("an internal error occured while performing the database operation")
+	ErrTaskCancelled       = errors.New("the task has been cancelled")
+	ErrTaskPaused          = errors.New("the task has been paused")
+	ErrTaskNotPaused       = errors.New("the task is not paused")
+	ErrScheduleExhausted   = errors.New("the schedule of the task has been exhausted")
+	ErrTaskStatusChanged   = errors.New("the status of the task was changed concurrently")
 )
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+// Get a summary of the progress of the tasks in the specified consignment.
+func GetConsignmentSummary(tenantID string,
+	consignmentID string) (*ConsignmentSummary, error) {
+	if tenantID == "" || consignmentID == "" {
+		schedLogger.Error("Invalid tenant ID or consignment ID specified!")
+		return nil, ErrInvalidRequest
+	}
+
+	summary := &ConsignmentSummary{
+		ConsignmentID: consignmentID,
+		TenantID:      tenantID,
+		StatusCounts:  map[string]int64{},
+	}
+
+	gSessionMutex.RLock()
+	defer gSessionMutex.RUnlock()
+
+	// The consignments table is sorted with the most recent task first, so the
+	// first activity is the creation of the last task in the partition.
+	err := gSession.Session.Query(`SELECT create_time FROM consignments WHERE tenant_id=? AND consignment_id=? ORDER BY task_id ASC LIMIT 1`,
+		tenantID, consignmentID).Scan(&summary.FirstActivity)
+	if err == gocql.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		schedLogger.Error("Failed to query the first task in the consignment!",
+			zap.String("Consignment ID:", consignmentID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	// The status counts of consignments created before the counts were kept
+	// are made up of the baseline counts written when the schema was migrated
+	// and the changes counted since.
+	var (
+		status string
+		count  int64
+	)
+	counts := map[string]int64{}
+	for _, table := range []string{"consignment_status_baselines",
+		"consignment_status_counts"} {
+		iter := gSession.Session.Query(`SELECT status, task_count FROM `+table+` WHERE tenant_id=? AND consignment_id=?`,
+			tenantID, consignmentID).Iter()
+		for iter.Scan(&status, &count) {
+			counts[status] += count
+		}
+		err = iter.Close()
+		if err != nil {
+			schedLogger.Error("Failed to query the task status counts for the consignment!",
+				zap.String("Consignment ID:", consignmentID),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+	}
+	for status, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		summary.StatusCounts[status] = count
+		summary.TaskCount += count
+	}
+
+	var lastActivity time.Time
+	err = gSession.Session.Query(`SELECT last_activity FROM consignment_activity WHERE tenant_id=? AND consignment_id=?`,
+		tenantID, consignmentID).Scan(&lastActivity)
+	if err != nil && err != gocql.ErrNotFound {
+		schedLogger.Error("Failed to query the last activity for the consignment!",
+			zap.String("Consignment ID:", consignmentID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	summary.LastActivity = lastActivity
+	if summary.LastActivity.IsZero() {
+		summary.LastActivity = summary.FirstActivity
+	}
+
+	if summary.TaskCount > 0 {
+		var finished int64
+		for _, taskStatus := range finishedTaskStatuses {
+			finished += summary.StatusCounts[taskStatus.String()]
+		}
+		summary.CompletionPercentage = float64(finished) * 100 /
+			float64(summary.TaskCount)
+	}
+
+	return summary, nil
+}
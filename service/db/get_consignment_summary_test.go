@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetConsignmentSummary(t *testing.T) {
+	tenantID := uuid.NewString()
+	consignmentID := uuid.NewString()
+
+	var tasks []*Task
+	for i := 0; i < 2; i++ {
+		newTask, err := NewTask(toStringPtr(tenantID),
+			toStringPtr(uuid.NewString()),
+			toStringPtr(consignmentID),
+			toBytePtr("Do something"))
+		if err != nil {
+			t.Errorf("Failed to initialize task with error %v\n", err)
+			return
+		}
+
+		err = newTask.CreateTask()
+		if err != nil {
+			t.Errorf("CreateTask failed with error %v\n", err)
+			return
+		}
+		tasks = append(tasks, newTask)
+	}
+
+	err := MarkTaskComplete(tasks[0])
+	if err != nil {
+		t.Errorf("MarkTaskComplete failed with error %v\n", err)
+		return
+	}
+
+	summary, err := GetConsignmentSummary(tenantID, consignmentID)
+	if err != nil {
+		t.Errorf("GetConsignmentSummary failed with error %v\n", err)
+		return
+	}
+
+	assertEqual(t, summary.TaskCount, int64(2))
+	assertEqual(t, summary.StatusCounts[TaskStatusQueued.String()], int64(1))
+	assertEqual(t, summary.StatusCounts[TaskStatusCompleted.String()], int64(1))
+	assertEqual(t, summary.CompletionPercentage, float64(50))
+}
+
+func TestGetConsignmentSummary_NotFound(t *testing.T) {
+	_, err := GetConsignmentSummary(uuid.NewString(), uuid.NewString())
+	assertEqual(t, err, ErrNotFound)
+}
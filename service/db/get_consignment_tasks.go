@@ -6,30 +6,32 @@ import (
 )
 
 // Get tasks queued within the scheduler database for the specified consignment
-// ID.
+// ID, optionally filtered by the specified criteria.
 func GetTasksForConsignment(tenantID string, consignmentID string,
-	startPage []byte, pageSize int) ([]*Consignment, []byte, error) {
+	filter *TaskFilter, startPage []byte, pageSize int) ([]*Consignment, []byte, error) {
 	if consignmentID == "" {
 		schedLogger.Error("Invalid consignment ID specified!")
 		return nil, nil, ErrInvalidRequest
 	}
 
 	var foundConsignments []*Consignment
+	values := qb.M{"tenant_id": tenantID, "consignment_id": consignmentID}
+	builder := qb.Select(consignmentsMetadata.Name).
+		Where(qb.Eq("tenant_id"), qb.Eq("consignment_id"))
+	filter.apply(builder, values)
+
 	gSessionMutex.RLock()
-	query := qb.Select(consignmentsMetadata.Name).
-		Where(qb.Eq("tenant_id"), qb.Eq("consignment_id")).
-		Query(gSession).
-		BindMap(qb.M{"tenant_id": tenantID, "consignment_id": consignmentID})
+	query := builder.Query(gSession).BindMap(values)
 	defer func() {
 		query.Release()
 		gSessionMutex.RUnlock()
 	}()
 
 	query.PageState(startPage)
-	if pageSize == 0 {
+	if pageSize <= 0 {
 		pageSize = itemsPerPage
 	}
-	query.PageSize(itemsPerPage)
+	query.PageSize(pageSize)
 
 	iter := query.Iter()
 	err := iter.Select(&foundConsignments)
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetTasksForConsignment(t *testing.T) {
+	tenantID := uuid.NewString()
+	consignmentID := uuid.NewString()
+
+	var tasks []*Task
+	for i := 0; i < 3; i++ {
+		newTask, err := NewTask(toStringPtr(tenantID),
+			toStringPtr(uuid.NewString()),
+			toStringPtr(consignmentID),
+			toBytePtr("Do something"))
+		if err != nil {
+			t.Errorf("Failed to initialize task with error %v\n", err)
+			return
+		}
+
+		err = newTask.CreateTask()
+		if err != nil {
+			t.Errorf("CreateTask failed with error %v\n", err)
+			return
+		}
+		tasks = append(tasks, newTask)
+	}
+
+	// Walk through all the tasks in the consignment one page at a time.
+	var (
+		pageState []byte
+		found     int
+	)
+	for {
+		page, nextPage, err := GetTasksForConsignment(tenantID, consignmentID,
+			nil, pageState, 1)
+		if err != nil {
+			t.Errorf("GetTasksForConsignment failed with error %v\n", err)
+			return
+		}
+		found += len(page)
+		if len(nextPage) == 0 {
+			break
+		}
+		pageState = nextPage
+	}
+	assertEqual(t, found, len(tasks))
+
+	// Filter the tasks by status and device.
+	err := MarkTaskComplete(tasks[1])
+	if err != nil {
+		t.Errorf("MarkTaskComplete failed with error %v\n", err)
+		return
+	}
+
+	page, _, err := GetTasksForConsignment(tenantID, consignmentID,
+		&TaskFilter{Status: TaskStatusCompleted.String()}, nil, 0)
+	if err != nil {
+		t.Errorf("GetTasksForConsignment failed with error %v\n", err)
+		return
+	}
+	assertEqual(t, len(page), 1)
+
+	page, _, err = GetTasksForConsignment(tenantID, consignmentID,
+		&TaskFilter{DeviceID: tasks[2].DeviceID.String()}, nil, 0)
+	if err != nil {
+		t.Errorf("GetTasksForConsignment failed with error %v\n", err)
+		return
+	}
+	assertEqual(t, len(page), 1)
+
+	// Filter the tasks by creation time.
+	page, _, err = GetTasksForConsignment(tenantID, consignmentID,
+		&TaskFilter{CreatedAfter: tasks[1].TaskID.Time()}, nil, 0)
+	if err != nil {
+		t.Errorf("GetTasksForConsignment failed with error %v\n", err)
+		return
+	}
+	assertEqual(t, len(page), 2)
+}
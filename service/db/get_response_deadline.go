@@ -0,0 +1,43 @@
+package db
+
+import (
+	"time"
+
+	"github.com/scylladb/gocqlx/v2/qb"
+	"go.uber.org/zap"
+)
+
+// Get response deadlines within the specified shard of the specified deadline
+// partition that have passed by the specified time.
+func GetResponseDeadlines(deadlinePartition string, shard int,
+	dueBy time.Time, startPage []byte) ([]*ResponseDeadline, []byte, error) {
+	var foundDeadlines []*ResponseDeadline
+
+	if deadlinePartition == "" {
+		return nil, nil, ErrInvalidRequest
+	}
+
+	gSessionMutex.RLock()
+	query := qb.Select(responseDeadlineMetadata.Name).
+		Where(qb.Eq("deadline_partition"), qb.Eq("shard"), qb.LtOrEq("deadline")).
+		Query(gSession).
+		Bind(deadlinePartition, shard, dueBy)
+	defer func() {
+		query.Release()
+		gSessionMutex.RUnlock()
+	}()
+
+	query.PageState(startPage)
+	query.PageSize(itemsPerPage)
+
+	iter := query.Iter()
+	err := iter.Select(&foundDeadlines)
+	if err != nil {
+		schedLogger.Error("Failed to query for response deadlines that have passed",
+			zap.Error(err),
+		)
+		return nil, nil, err
+	}
+
+	return foundDeadlines, iter.PageState(), nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"github.com/scylladb/gocqlx/v2/qb"
+	"go.uber.org/zap"
+)
+
+// Get the rollout of the specified consignment.
+func GetRollout(tenantID string, consignmentID string) (*Rollout, error) {
+	if tenantID == "" || consignmentID == "" {
+		schedLogger.Error("Invalid tenant ID or consignment ID specified!")
+		return nil, ErrInvalidRequest
+	}
+
+	var foundRollouts []*Rollout
+	gSessionMutex.RLock()
+	err := qb.Select(rolloutMetadata.Name).
+		Where(qb.Eq("tenant_id"), qb.Eq("consignment_id")).
+		Query(gSession).
+		Bind(tenantID, consignmentID).
+		SelectRelease(&foundRollouts)
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to query the rollout of the consignment!",
+			zap.String("Tenant ID:", tenantID),
+			zap.String("Consignment ID:", consignmentID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	if len(foundRollouts) == 0 {
+		return nil, ErrNotFound
+	}
+	return foundRollouts[0], nil
+}
+
+// Get a page of the rollouts in the scheduler database, starting from the
+// specified page.
+func GetRollouts(startPage []byte) ([]*Rollout, []byte, error) {
+	var foundRollouts []*Rollout
+
+	gSessionMutex.RLock()
+	query := qb.Select(rolloutMetadata.Name).Query(gSession)
+	defer func() {
+		query.Release()
+		gSessionMutex.RUnlock()
+	}()
+
+	query.PageState(startPage)
+	query.PageSize(itemsPerPage)
+
+	iter := query.Iter()
+	err := iter.Select(&foundRollouts)
+	if err != nil {
+		schedLogger.Error("Failed to query for rollouts",
+			zap.Error(err),
+		)
+		return nil, nil, err
+	}
+
+	return foundRollouts, iter.PageState(), nil
+}
+
+// Get a page of the devices in the specified stage of the rollout, starting
+// from the specified page.
+func (r *Rollout) GetRolloutDevices(stage int,
+	startPage []byte) ([]string, []byte, error) {
+	gSessionMutex.RLock()
+	defer gSessionMutex.RUnlock()
+
+	iter := gSession.Session.Query(`SELECT device_id FROM rollout_devices WHERE tenant_id=? AND consignment_id=? AND stage=?`,
+		r.TenantID, r.ConsignmentID, stage).
+		PageState(startPage).
+		PageSize(itemsPerPage).
+		Iter()
+
+	var (
+		deviceID string
+		devices  []string
+	)
+	for iter.Scan(&deviceID) {
+		devices = append(devices, deviceID)
+	}
+	nextPage := iter.PageState()
+	err := iter.Close()
+	if err != nil {
+		schedLogger.Error("Failed to query the devices of the rollout!",
+			zap.String("Tenant ID:", r.TenantID),
+			zap.String("Consignment ID:", r.ConsignmentID),
+			zap.Int("Stage:", stage),
+			zap.Error(err),
+		)
+		return nil, nil, err
+	}
+
+	return devices, nextPage, nil
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"github.com/scylladb/gocqlx/v2/qb"
 	"go.uber.org/zap"
 )
@@ -10,9 +12,10 @@ const (
 	itemsPerPage = 100
 )
 
-// Get scheduled runs within the specified run partition.
-func GetScheduledRuns(runPartition string, startPage []byte) ([]*ScheduledRun,
-	[]byte, error) {
+// Get scheduled runs within the specified shard of the specified run partition
+// that are due to run at or before the specified time.
+func GetScheduledRuns(runPartition string, shard int, dueBy time.Time,
+	startPage []byte) ([]*ScheduledRun, []byte, error) {
 	var foundSchedules []*ScheduledRun
 
 	if runPartition == "" {
@@ -21,9 +24,9 @@ func GetScheduledRuns(runPartition string, startPage []byte) ([]*ScheduledRun,
 
 	gSessionMutex.RLock()
 	query := qb.Select(scheduledRunMetadata.Name).
-		Where(qb.Eq("run_partition")).
+		Where(qb.Eq("run_partition"), qb.Eq("shard"), qb.LtOrEq("next_run")).
 		Query(gSession).
-		Bind(runPartition)
+		Bind(runPartition, shard, dueBy)
 	defer func() {
 		query.Release()
 		gSessionMutex.RUnlock()
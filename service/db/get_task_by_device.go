@@ -6,27 +6,43 @@ import (
 	"go.uber.org/zap"
 )
 
-// Get tasks queued within the scheduler database for the specified device ID.
-func (t *Task) GetTasksForDeviceID(deviceID string) ([]*Task, error) {
+// Get tasks queued within the scheduler database for the specified device ID,
+// optionally filtered by the specified criteria.
+func (t *Task) GetTasksForDeviceID(deviceID string, filter *TaskFilter,
+	startPage []byte, pageSize int) ([]*Task, []byte, error) {
 
 	if deviceID == "" {
 		schedLogger.Error("Invalid device ID specified!")
-		return nil, ErrInvalidRequest
+		return nil, nil, ErrInvalidRequest
 	}
 
 	var foundTasks []*Task
+	values := qb.M{"device_id": deviceID}
+	builder := qb.Select(taskMetadata.Name).
+		Where(qb.Eq("device_id"))
+	filter.apply(builder, values)
+
 	gSessionMutex.RLock()
-	err := qb.Select(taskMetadata.Name).
-		Where(qb.EqLit("device_id", deviceID)).
-		Query(gSession).
-		SelectRelease(&foundTasks)
-	gSessionMutex.RUnlock()
+	query := builder.Query(gSession).BindMap(values)
+	defer func() {
+		query.Release()
+		gSessionMutex.RUnlock()
+	}()
+
+	query.PageState(startPage)
+	if pageSize <= 0 {
+		pageSize = itemsPerPage
+	}
+	query.PageSize(pageSize)
+
+	iter := query.Iter()
+	err := iter.Select(&foundTasks)
 	if err != nil {
 		schedLogger.Error("Failed to execute query!",
 			zap.String("Device ID: ", deviceID),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, nil, err
 	}
 
 	schedLogger.Debug("Executed get tasks for device ID query",
@@ -34,7 +50,7 @@ func (t *Task) GetTasksForDeviceID(deviceID string) ([]*Task, error) {
 		zap.Int("Number of tasks found:", len(foundTasks)),
 		zap.Any("Task:", foundTasks),
 	)
-	return foundTasks, nil
+	return foundTasks, iter.PageState(), nil
 }
 
 // Get the specified task queued within the scheduler database for the specified
@@ -24,7 +24,8 @@ func TestGetTaskByDeviceID(t *testing.T) {
 
 	t.Logf("Created task with details : %+v\n", newTask)
 
-	foundTask, err := newTask.GetTasksForDeviceID(newTask.DeviceID.String())
+	foundTask, _, err := newTask.GetTasksForDeviceID(newTask.DeviceID.String(),
+		nil, nil, 0)
 	if err != nil {
 		t.Errorf("GetTaskByDeviceID failed with error %v\n", err)
 		return
@@ -53,7 +54,8 @@ func TestGetMultipleTasksByDeviceID(t *testing.T) {
 		t.Logf("Created task with details : %+v\n", newTask)
 	}
 
-	foundTask, err := newTask.GetTasksForDeviceID(newTask.DeviceID.String())
+	foundTask, _, err := newTask.GetTasksForDeviceID(newTask.DeviceID.String(),
+		nil, nil, 0)
 	if err != nil {
 		t.Errorf("GetTaskByDeviceID failed with error %v\n", err)
 		return
@@ -64,13 +66,14 @@ func TestGetMultipleTasksByDeviceID(t *testing.T) {
 
 func TestGetTaskByDeviceID_InvalidDeviceID(t *testing.T) {
 	newTask := Task{}
-	_, err := newTask.GetTasksForDeviceID("")
+	_, _, err := newTask.GetTasksForDeviceID("", nil, nil, 0)
 	assertEqual(t, err, ErrInvalidRequest)
 }
 
 func TestGetTaskByDeviceID_UnknownDeviceID(t *testing.T) {
 	newTask := Task{}
-	foundTask, err := newTask.GetTasksForDeviceID(uuid.NewString())
+	foundTask, _, err := newTask.GetTasksForDeviceID(uuid.NewString(), nil,
+		nil, 0)
 	if err != nil {
 		t.Errorf("GetTaskByDeviceID failed with error %v\n", err)
 		return
@@ -78,3 +81,39 @@ func TestGetTaskByDeviceID_UnknownDeviceID(t *testing.T) {
 
 	t.Logf("Found task with details %+v\n", foundTask)
 }
+
+func TestGetTaskByDeviceID_Filter(t *testing.T) {
+	newTask, err := NewTask(toStringPtr(uuid.NewString()),
+		toStringPtr(uuid.NewString()),
+		toStringPtr(uuid.NewString()),
+		toBytePtr("Do something"))
+	if err != nil {
+		t.Errorf("Failed to initialize task with error %v\n", err)
+		return
+	}
+
+	for _, messageType := range []string{"A", "B", "B"} {
+		newTask.MessageType = messageType
+		err := newTask.CreateTask()
+		if err != nil {
+			t.Errorf("CreateTask failed with error %v\n", err)
+			return
+		}
+	}
+
+	foundTasks, _, err := newTask.GetTasksForDeviceID(newTask.DeviceID.String(),
+		&TaskFilter{TenantID: newTask.TenantID, MessageType: "B"}, nil, 0)
+	if err != nil {
+		t.Errorf("GetTaskByDeviceID failed with error %v\n", err)
+		return
+	}
+	assertEqual(t, len(foundTasks), 2)
+
+	foundTasks, _, err = newTask.GetTasksForDeviceID(newTask.DeviceID.String(),
+		&TaskFilter{TenantID: uuid.NewString()}, nil, 0)
+	if err != nil {
+		t.Errorf("GetTaskByDeviceID failed with error %v\n", err)
+		return
+	}
+	assertEqual(t, len(foundTasks), 0)
+}
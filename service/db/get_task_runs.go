@@ -0,0 +1,48 @@
+package db
+
+import (
+	"github.com/gocql/gocql"
+	"github.com/scylladb/gocqlx/v2/qb"
+	"go.uber.org/zap"
+)
+
+// Get the runs of the specified task, starting with the most recent run.
+func GetTaskRuns(taskID string, startPage []byte,
+	pageSize int) ([]*TaskRun, []byte, error) {
+	parsedTaskID, err := gocql.ParseUUID(taskID)
+	if err != nil {
+		schedLogger.Error("Invalid task ID specified!",
+			zap.String("Task ID:", taskID),
+		)
+		return nil, nil, ErrInvalidRequest
+	}
+
+	var foundRuns []*TaskRun
+	gSessionMutex.RLock()
+	query := qb.Select(taskRunMetadata.Name).
+		Where(qb.Eq("task_id")).
+		Query(gSession).
+		Bind(parsedTaskID)
+	defer func() {
+		query.Release()
+		gSessionMutex.RUnlock()
+	}()
+
+	query.PageState(startPage)
+	if pageSize <= 0 {
+		pageSize = itemsPerPage
+	}
+	query.PageSize(pageSize)
+
+	iter := query.Iter()
+	err = iter.Select(&foundRuns)
+	if err != nil {
+		schedLogger.Error("Failed to query for runs of the task!",
+			zap.String("Task ID:", taskID),
+			zap.Error(err),
+		)
+		return nil, nil, err
+	}
+
+	return foundRuns, iter.PageState(), nil
+}
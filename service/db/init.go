@@ -76,6 +76,9 @@ func Init(logger *zap.Logger, cfgMgr *config.ConfigMgr) error {
 	createScheduledRunStatements()
 	createConsignmentStatements()
 	createRegisteredServiceStatements()
+	createResponseDeadlineStatements()
+	createTaskRunStatements()
+	createRolloutStatements()
 
 	// Initialize the service dispatch lookup table which maintains a mapping
 	// between MQTT topic and corresponding service queue topic for each
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"time"
+)
+
+// Represents a lease on a unit of work claimed by a scheduler instance. A lease
+// is held by its owner until it expires, unless it is renewed before then.
+type Lease struct {
+	// The name of the unit of work covered by the lease.
+	Name string `db:"lease_name" json:"lease_name"`
+
+	// The identifier of the scheduler instance holding the lease.
+	Owner string `db:"owner" json:"owner"`
+
+	// The time at which the lease expires.
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
@@ -21,6 +21,15 @@ func migrateDatabaseSchema() error {
 		return nil
 	}
 
+	// Register callbacks invoked by migration scripts that require data to be
+	// migrated in addition to the schema.
+	callbacks := migrate.CallbackRegister{}
+	callbacks.Add(migrate.CallComment, copyScheduledRunsToBucketsCallback,
+		copyScheduledRunsToBuckets)
+	callbacks.Add(migrate.CallComment, countConsignmentStatusesCallback,
+		countConsignmentStatuses)
+	migrate.Callback = callbacks.Callback
+
 	ctx := context.Background()
 	gSessionMutex.Lock()
 	err := migrate.FromFS(ctx, gSession, os.DirFS(dbConfig.SchemaMigrationScripts))
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/scylladb/gocqlx/v2"
+	"github.com/scylladb/gocqlx/v2/migrate"
+	"go.uber.org/zap"
+)
+
+const (
+	// Name of the migration callback that counts the statuses of tasks in
+	// existing consignments.
+	countConsignmentStatusesCallback = "count_consignment_statuses"
+)
+
+// Count the statuses of tasks in existing consignments and record the time at
+// which the most recent task in each consignment was created. The counts are
+// written to the baseline status counts of each consignment, which are
+// overwritten if the migration is re-run. This is invoked by the schema
+// migration script while the session lock is held, so the specified session
+// must be used.
+func countConsignmentStatuses(ctx context.Context, session gocqlx.Session,
+	ev migrate.CallbackEvent, name string) error {
+	var (
+		tenantID      string
+		consignmentID string
+		status        string
+		createTime    time.Time
+		counted       int
+	)
+
+	// Rows of the same consignment are returned together, as they are stored
+	// in the same partition.
+	pending := newConsignmentStatusCount("", "")
+	iter := session.Session.Query(`SELECT tenant_id, consignment_id, status, create_time FROM consignments`).
+		WithContext(ctx).Iter()
+	for iter.Scan(&tenantID, &consignmentID, &status, &createTime) {
+		if tenantID != pending.tenantID ||
+			consignmentID != pending.consignmentID {
+			err := pending.write(ctx, session)
+			if err != nil {
+				_ = iter.Close()
+				return err
+			}
+			pending = newConsignmentStatusCount(tenantID, consignmentID)
+		}
+
+		pending.counts[status]++
+		if createTime.After(pending.lastActivity) {
+			pending.lastActivity = createTime
+		}
+		counted++
+	}
+
+	err := iter.Close()
+	if err != nil {
+		schedLogger.Error("Failed to read tasks from the consignments table!",
+			zap.Error(err),
+		)
+		return err
+	}
+
+	err = pending.write(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	schedLogger.Info("Counted the statuses of tasks in existing consignments!",
+		zap.Int("Tasks:", counted),
+	)
+	return nil
+}
+
+// The counts of the statuses of the tasks in a consignment, along with the
+// time at which the most recent task in the consignment was created.
+type consignmentStatusCount struct {
+	tenantID      string
+	consignmentID string
+	counts        map[string]int64
+	lastActivity  time.Time
+}
+
+func newConsignmentStatusCount(tenantID string,
+	consignmentID string) *consignmentStatusCount {
+	return &consignmentStatusCount{
+		tenantID:      tenantID,
+		consignmentID: consignmentID,
+		counts:        map[string]int64{},
+	}
+}
+
+// Write the baseline status counts and the last activity of the consignment.
+func (c *consignmentStatusCount) write(ctx context.Context,
+	session gocqlx.Session) error {
+	if len(c.counts) == 0 {
+		return nil
+	}
+
+	batch := session.Session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	for status, count := range c.counts {
+		batch.Query(`INSERT INTO consignment_status_baselines (tenant_id, consignment_id, status, task_count) VALUES (?, ?, ?, ?)`,
+			c.tenantID, c.consignmentID, status, count)
+	}
+	err := session.Session.ExecuteBatch(batch)
+	if err == nil {
+		err = session.Session.Query(`UPDATE consignment_activity SET last_activity=? WHERE tenant_id=? AND consignment_id=?`,
+			c.lastActivity, c.tenantID, c.consignmentID).WithContext(ctx).Exec()
+	}
+	if err != nil {
+		schedLogger.Error("Failed to count the statuses of the tasks in a consignment!",
+			zap.String("Tenant ID:", c.tenantID),
+			zap.String("Consignment ID:", c.consignmentID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
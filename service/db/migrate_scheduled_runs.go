@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+
+	"github.com/scylladb/gocqlx/v2"
+	"github.com/scylladb/gocqlx/v2/migrate"
+	"go.uber.org/zap"
+)
+
+const (
+	// Name of the migration callback that copies scheduled runs from the
+	// original scheduled runs table into the bucketed scheduled runs table.
+	copyScheduledRunsToBucketsCallback = "copy_scheduled_runs_to_buckets"
+)
+
+// Copy scheduled runs stored in the original day partitioned scheduled runs
+// table into the scheduled runs table partitioned by time bucket and shard.
+// This is invoked by the schema migration script while the session lock is
+// held, so the specified session must be used.
+func copyScheduledRunsToBuckets(ctx context.Context, session gocqlx.Session,
+	ev migrate.CallbackEvent, name string) error {
+	var (
+		run    ScheduledRun
+		copied int
+	)
+
+	iter := session.Session.Query(`SELECT next_run, last_run, task_id, device_id FROM scheduled_runs`).
+		WithContext(ctx).Iter()
+	for iter.Scan(&run.NextRun, &run.LastRun, &run.TaskID, &run.DeviceID) {
+		err := session.Session.Query(`INSERT INTO scheduled_run_buckets (run_partition, shard, next_run, task_id, last_run, device_id) VALUES (?, ?, ?, ?, ?, ?)`,
+			GetRunPartition(run.NextRun), GetRunShard(run.TaskID), run.NextRun,
+			run.TaskID, run.LastRun, run.DeviceID).WithContext(ctx).Exec()
+		if err != nil {
+			schedLogger.Error("Failed to copy a scheduled run into the bucketed scheduled runs table!",
+				zap.String("Task ID:", run.TaskID.String()),
+				zap.Error(err),
+			)
+			_ = iter.Close()
+			return err
+		}
+		copied++
+	}
+
+	err := iter.Close()
+	if err != nil {
+		schedLogger.Error("Failed to read scheduled runs from the scheduled runs table!",
+			zap.Error(err),
+		)
+		return err
+	}
+
+	schedLogger.Info("Copied scheduled runs into the bucketed scheduled runs table!",
+		zap.Int("Scheduled runs:", copied),
+	)
+	return nil
+}
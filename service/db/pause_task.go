@@ -0,0 +1,170 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+// Pause the specified task. The task is marked as paused provided that its
+// status has not changed since it was read, otherwise ErrTaskStatusChanged is
+// returned. Its scheduled run and any pending retry are then removed, so that
+// the task is not dispatched while it is paused. The time of the next run is
+// kept with the task, so that the runs missed while the task was paused can be
+// determined when it is resumed.
+func PauseTask(task *Task) error {
+	pausedAt := time.Now()
+
+	err := updateTaskIfStatus(task.DeviceID, task.TaskID,
+		[]string{task.Status},
+		"status=?, paused_at=?, paused_status=?, retry_run=null",
+		TaskStatusPaused.String(), pausedAt, task.Status)
+	if err == nil {
+		gSessionMutex.RLock()
+		batch := gSession.Session.NewBatch(gocql.LoggedBatch)
+		removeScheduledRunsForTask(batch, task)
+		if batch.Size() > 0 {
+			err = gSession.Session.ExecuteBatch(batch)
+		}
+		gSessionMutex.RUnlock()
+	}
+	if err != nil {
+		schedLogger.Error("Failed to pause the task!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.String("Device ID:", task.DeviceID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	task.PausedStatus = task.Status
+	task.Status = TaskStatusPaused.String()
+	task.PausedAt = pausedAt
+	task.RetryRun = time.Time{}
+
+	return UpdateConsignmentTaskStatus(task.TenantID, task.ConsignmentID,
+		task.TaskID.String(), TaskStatusPaused)
+}
+
+// Record the specified status of a paused task, reported by the device for a
+// run dispatched before the task was paused. The task stays paused and returns
+// to the recorded status when it is resumed. Returns ErrTaskStatusChanged if
+// the task is no longer paused.
+func SetPausedTaskStatus(task *Task, status TaskStatus) error {
+	applied, previous, err := updateTaskIf(task.DeviceID, task.TaskID,
+		"paused_status=?", []interface{}{status.String()}, "status=?",
+		TaskStatusPaused.String())
+	if err != nil {
+		schedLogger.Error("Failed to record the status of the paused task!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.String("Device ID:", task.DeviceID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+	if !applied {
+		return taskStatusError(previous["status"])
+	}
+
+	task.PausedStatus = status.String()
+	return nil
+}
+
+// Resume the specified paused task. The task returns to the status it had
+// when it was paused, or to the status reported by the device while it was
+// paused. If the time of the next run is specified, a scheduled run of the task
+// is created at that time. If the time of a retry is specified, the retry
+// dropped when the task was paused is scheduled again at that time. The task
+// is only resumed if it is still paused, otherwise the scheduled runs are
+// removed again and ErrTaskNotPaused is returned. If the status to return to
+// has changed since the task was read, ErrTaskStatusChanged is returned
+// instead.
+func ResumeTask(task *Task, nextRun time.Time, retryRun time.Time) error {
+	status := task.PausedStatus
+	if status == "" {
+		status = TaskStatusQueued.String()
+	}
+
+	assignments := "status=?, paused_at=null, paused_status=null"
+	values := []interface{}{status}
+
+	var runs []ScheduledRun
+	for _, run := range []ScheduledRun{{NextRun: nextRun},
+		{NextRun: retryRun, IsRetry: true}} {
+		if run.NextRun.IsZero() {
+			continue
+		}
+
+		run.RunPartition = GetRunPartition(run.NextRun)
+		run.Shard = GetRunShard(task.TaskID)
+		run.TaskID = task.TaskID
+		run.DeviceID = task.DeviceID
+		run.LastRun = task.CurrentRun
+		if run.IsRetry {
+			assignments += ", retry_run=?"
+		} else {
+			assignments += ", next_run=?"
+		}
+		values = append(values, run.NextRun)
+
+		gSessionMutex.RLock()
+		err := gSession.Session.Query(`INSERT INTO scheduled_run_buckets (run_partition, shard, next_run, task_id, last_run, device_id, is_retry) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			run.RunPartition, run.Shard, run.NextRun, run.TaskID, run.LastRun,
+			run.DeviceID, run.IsRetry).Exec()
+		gSessionMutex.RUnlock()
+		if err != nil {
+			for _, created := range runs {
+				_ = created.deleteRunEntry()
+			}
+			schedLogger.Error("Failed to resume the task!",
+				zap.String("Task ID:", task.TaskID.String()),
+				zap.String("Device ID:", task.DeviceID.String()),
+				zap.Error(err),
+			)
+			return err
+		}
+		runs = append(runs, run)
+	}
+
+	condition := "status=? AND paused_status=?"
+	conditionValues := []interface{}{TaskStatusPaused.String(),
+		task.PausedStatus}
+	if task.PausedStatus == "" {
+		condition = "status=? AND paused_status=null"
+		conditionValues = conditionValues[:1]
+	}
+
+	applied, previous, err := updateTaskIf(task.DeviceID, task.TaskID,
+		assignments, values, condition, conditionValues...)
+	if err != nil || !applied {
+		for _, created := range runs {
+			_ = created.deleteRunEntry()
+		}
+		if err != nil {
+			schedLogger.Error("Failed to resume the task!",
+				zap.String("Task ID:", task.TaskID.String()),
+				zap.String("Device ID:", task.DeviceID.String()),
+				zap.Error(err),
+			)
+			return err
+		}
+		if previous["status"] == TaskStatusPaused.String() {
+			return ErrTaskStatusChanged
+		}
+		return ErrTaskNotPaused
+	}
+
+	task.Status = status
+	task.PausedStatus = ""
+	task.PausedAt = time.Time{}
+	if !nextRun.IsZero() {
+		task.NextRun = nextRun
+	}
+	if !retryRun.IsZero() {
+		task.RetryRun = retryRun
+	}
+
+	return UpdateConsignmentTaskStatus(task.TenantID, task.ConsignmentID,
+		task.TaskID.String(), parseTaskStatus(status))
+}
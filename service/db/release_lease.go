@@ -0,0 +1,23 @@
+package db
+
+import (
+	"go.uber.org/zap"
+)
+
+// Release the specified lease if it is held by the specified owner, so that
+// another scheduler instance can take it over right away.
+func ReleaseLease(leaseName string, owner string) error {
+	gSessionMutex.RLock()
+	_, err := gSession.Session.Query(`DELETE FROM scheduler_leases WHERE lease_name=? IF owner=?`,
+		leaseName, owner).MapScanCAS(map[string]interface{}{})
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to release the lease in the scheduler database!",
+			zap.String("Lease:", leaseName),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"go.uber.org/zap"
+)
+
+// Remove the response deadline from the scheduler database.
+func (d *ResponseDeadline) RemoveResponseDeadline() error {
+	gSessionMutex.RLock()
+	err := gSession.Query(responseDeadlinesStatements.delete.statement,
+		responseDeadlinesStatements.delete.names).
+		BindStruct(d).
+		ExecRelease()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to remove the response deadline from the scheduler database!",
+			zap.String("Task ID:", d.TaskID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
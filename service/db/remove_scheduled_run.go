@@ -0,0 +1,102 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+// Remove the scheduled run from the scheduler database. This is done once a
+// task that does not recur has been dispatched, once a retry has been
+// dispatched or once the task has been removed. The time of the run recorded
+// with the task is cleared within the same logged batch, so that the task is
+// known to have no such run.
+func (s *ScheduledRun) RemoveScheduledRun() error {
+	taskUpdate := `UPDATE tasks SET next_run=null WHERE device_id=? AND task_id=?`
+	if s.IsRetry {
+		taskUpdate = `UPDATE tasks SET retry_run=null WHERE device_id=? AND task_id=?`
+	}
+
+	gSessionMutex.RLock()
+	batch := gSession.Session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM scheduled_run_buckets WHERE run_partition=? AND shard=? AND next_run=? AND task_id=?`,
+		s.RunPartition, s.Shard, s.NextRun, s.TaskID)
+	batch.Query(taskUpdate, s.DeviceID, s.TaskID)
+	err := gSession.Session.ExecuteBatch(batch)
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to remove the scheduled run from the scheduler database!",
+			zap.String("Task ID:", s.TaskID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Remove the entry of the scheduled run, without changing the time of the
+// next run recorded with the task. This is used for the runs of paused tasks,
+// whose time is kept so that the runs missed while the task was paused can be
+// determined when it is resumed, and for runs superseded by another run of
+// the task.
+func (s *ScheduledRun) RemoveRunEntry() error {
+	err := s.deleteRunEntry()
+	if err != nil {
+		schedLogger.Error("Failed to remove the scheduled run from the scheduler database!",
+			zap.String("Task ID:", s.TaskID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Delete the entry of the scheduled run from the scheduled runs table.
+func (s *ScheduledRun) deleteRunEntry() error {
+	gSessionMutex.RLock()
+	defer gSessionMutex.RUnlock()
+	return gSession.Query(scheduledRunsStatements.delete.statement,
+		scheduledRunsStatements.delete.names).
+		BindStruct(s).
+		ExecRelease()
+}
+
+// Remove the scheduled run of the specified task, which has reached the end of
+// its bounded schedule. The time of the next run of the task is cleared within
+// the same logged batch, so that the task is known to have no further runs.
+func (s *ScheduledRun) EndSchedule(task *Task) error {
+	gSessionMutex.RLock()
+	batch := gSession.Session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM scheduled_run_buckets WHERE run_partition=? AND shard=? AND next_run=? AND task_id=?`,
+		s.RunPartition, s.Shard, s.NextRun, s.TaskID)
+	batch.Query(`UPDATE tasks SET next_run=null WHERE device_id=? AND task_id=?`,
+		s.DeviceID, s.TaskID)
+	err := gSession.Session.ExecuteBatch(batch)
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to end the schedule of the task in the scheduler database!",
+			zap.String("Task ID:", s.TaskID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	task.NextRun = time.Time{}
+	return nil
+}
+
+// Add queries to the specified batch to remove the scheduled run and any
+// pending retry of the specified task.
+func removeScheduledRunsForTask(batch *gocql.Batch, task *Task) {
+	for _, runTime := range []time.Time{task.NextRun, task.RetryRun} {
+		if runTime.IsZero() {
+			continue
+		}
+		batch.Query(`DELETE FROM scheduled_run_buckets WHERE run_partition=? AND shard=? AND next_run=? AND task_id=?`,
+			GetRunPartition(runTime), GetRunShard(task.TaskID), runTime,
+			task.TaskID)
+	}
+}
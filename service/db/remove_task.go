@@ -5,9 +5,12 @@ import (
 	"go.uber.org/zap"
 )
 
-// Remove the specified task from the scheduler database.
+// Remove the specified task from the scheduler database. The task, its entry
+// in its consignment, its scheduled run, any pending retry and its run history
+// are removed within a single logged batch. Returns ErrNotFound if the task
+// does not exist.
 func (t *Task) RemoveTask(taskID string, deviceID string) error {
-	parsedTaskID, err := gocql.ParseUUID(taskID)
+	_, err := gocql.ParseUUID(taskID)
 	if err != nil {
 		schedLogger.Error("Failed to parse the specified task ID",
 			zap.String("Specified Task ID:", taskID),
@@ -16,7 +19,7 @@ func (t *Task) RemoveTask(taskID string, deviceID string) error {
 		return ErrInvalidRequest
 	}
 
-	parsedDeviceID, err := gocql.ParseUUID(deviceID)
+	_, err = gocql.ParseUUID(deviceID)
 	if err != nil {
 		schedLogger.Error("Failed to parse the specified device ID",
 			zap.String("Specified Task ID:", deviceID),
@@ -25,16 +28,26 @@ func (t *Task) RemoveTask(taskID string, deviceID string) error {
 		return ErrInvalidRequest
 	}
 
-	delTask := Task{
-		TaskID:   parsedTaskID,
-		DeviceID: parsedDeviceID,
+	delTask, err := GetTaskByID(taskID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	consignmentStatus, err := getConsignmentTaskStatus(delTask.TenantID,
+		delTask.ConsignmentID, taskID)
+	if err != nil && err != ErrNotFound {
+		return err
 	}
 
 	gSessionMutex.RLock()
-	err = gSession.Query(tasksStatements.delete.statement,
-		tasksStatements.delete.names).
-		BindStruct(delTask).
-		ExecRelease()
+	batch := gSession.Session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM tasks WHERE device_id=? AND task_id=?`,
+		delTask.DeviceID, delTask.TaskID)
+	batch.Query(`DELETE FROM consignments WHERE tenant_id=? AND consignment_id=? AND task_id=?`,
+		delTask.TenantID, delTask.ConsignmentID, delTask.TaskID)
+	batch.Query(`DELETE FROM task_runs WHERE task_id=?`, delTask.TaskID)
+	removeScheduledRunsForTask(batch, delTask)
+	err = gSession.Session.ExecuteBatch(batch)
 	gSessionMutex.RUnlock()
 	if err != nil {
 		schedLogger.Error("Failed to remove the specified task from the scheduler database!",
@@ -44,5 +57,9 @@ func (t *Task) RemoveTask(taskID string, deviceID string) error {
 		return err
 	}
 
-	return nil
+	if consignmentStatus == "" {
+		return nil
+	}
+	return updateConsignmentSummary(delTask.TenantID, delTask.ConsignmentID,
+		consignmentStatus, "")
 }
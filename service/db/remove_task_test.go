@@ -3,6 +3,7 @@ package db
 import (
 	"testing"
 
+	"github.com/gocql/gocql"
 	"github.com/google/uuid"
 )
 
@@ -40,3 +41,9 @@ func TestRemoveTask_InvalidTaskID(t *testing.T) {
 	err := removeTask.RemoveTask("abdcs", uuid.NewString())
 	assertEqual(t, err, ErrInvalidRequest)
 }
+
+func TestRemoveTask_NotFound(t *testing.T) {
+	removeTask := Task{}
+	err := removeTask.RemoveTask(gocql.TimeUUID().String(), uuid.NewString())
+	assertEqual(t, err, ErrNotFound)
+}
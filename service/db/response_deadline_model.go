@@ -0,0 +1,87 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/scylladb/gocqlx/v2/qb"
+	"github.com/scylladb/gocqlx/v2/table"
+)
+
+var (
+	// Metadata describing the response deadlines table in the scheduler
+	// database.
+	responseDeadlineMetadata table.Metadata
+
+	responseDeadlinesTable *table.Table
+
+	// Pre-created CQL query statements to interact with the response deadlines
+	// table.
+	responseDeadlinesStatements *statements
+)
+
+// Represents the deadline by which a device must respond to a task dispatched
+// to it.
+type ResponseDeadline struct {
+	// The time bucket in which the deadline falls.
+	DeadlinePartition string `db:"deadline_partition" json:"deadline_partition"`
+
+	// The shard within the time bucket to which the deadline is assigned.
+	Shard int `db:"shard" json:"shard"`
+
+	// The time by which the device must respond to the task.
+	Deadline time.Time `db:"deadline" json:"deadline"`
+
+	// Unique identifier assigned to a task.
+	TaskID gocql.UUID `db:"task_id" json:"task_id"`
+
+	// The unique identifier associated with the device.
+	DeviceID gocql.UUID `db:"device_id" json:"device_id"`
+}
+
+func createResponseDeadlineStatements() {
+	// Metadata describing the response deadlines table in the scheduler
+	// database.
+	responseDeadlineMetadata = table.Metadata{
+		Name: "response_deadlines",
+		Columns: []string{
+			"deadline_partition",
+			"shard",
+			"deadline",
+			"task_id",
+			"device_id",
+		},
+		PartKey: []string{
+			"deadline_partition",
+			"shard",
+		},
+		SortKey: []string{
+			"deadline",
+			"task_id",
+		},
+	}
+
+	responseDeadlinesTable = table.New(responseDeadlineMetadata)
+
+	// Store pre-created CQL query statements to interact with the response
+	// deadlines table.
+	deleteStatement, deleteNames := responseDeadlinesTable.Delete()
+	insertStatement, insertNames := responseDeadlinesTable.Insert()
+	getStatement, getNames := qb.Select(responseDeadlineMetadata.Name).
+		Columns(responseDeadlineMetadata.Columns...).ToCql()
+
+	responseDeadlinesStatements = &statements{
+		delete: query{
+			statement: deleteStatement,
+			names:     deleteNames,
+		},
+		insert: query{
+			statement: insertStatement,
+			names:     insertNames,
+		},
+		get: query{
+			statement: getStatement,
+			names:     getNames,
+		},
+	}
+}
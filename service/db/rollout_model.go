@@ -0,0 +1,125 @@
+package db
+
+import (
+	"time"
+
+	"github.com/scylladb/gocqlx/v2/qb"
+	"github.com/scylladb/gocqlx/v2/table"
+)
+
+var (
+	// Metadata describing the rollouts table in the scheduler database.
+	rolloutMetadata table.Metadata
+
+	rolloutsTable *table.Table
+
+	// Pre-created CQL query statements to interact with the rollouts table.
+	rolloutsStatements *statements
+)
+
+// Statuses of a staged rollout of a consignment.
+const (
+	// The rollout is being created and its devices are being recorded.
+	RolloutStatusStarting = "starting"
+
+	// The rollout advances to its next stage once the current stage is over.
+	RolloutStatusInProgress = "in_progress"
+
+	// The rollout was paused, either because too many tasks in the
+	// consignment failed or because the consignment was paused.
+	RolloutStatusPaused = "paused"
+
+	// Tasks have been scheduled for the devices in every stage.
+	RolloutStatusCompleted = "completed"
+
+	// The consignment was cancelled before the rollout completed.
+	RolloutStatusCancelled = "cancelled"
+)
+
+// Represents a staged rollout of the tasks in a consignment, in which tasks are
+// scheduled for a growing proportion of the devices in the consignment.
+type Rollout struct {
+	// The tenant ID to which the devices in the consignment belong.
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+
+	// The consignment ID assigned by the service which requested the tasks.
+	ConsignmentID string `db:"consignment_id" json:"consignment_id"`
+
+	// The protobuf encoded request for the consignment, without its device
+	// IDs, used to schedule tasks for the devices in later stages.
+	Request []byte `db:"request" json:"-"`
+
+	// The index of the most recent stage of the rollout to have started.
+	Stage int `db:"stage" json:"stage"`
+
+	// The status of the rollout.
+	Status string `db:"status" json:"status"`
+
+	// The time at which the rollout advances to its next stage.
+	NextStageAt time.Time `db:"next_stage_at" json:"next_stage_at,omitempty"`
+
+	// The number of failures and responses in the consignment when the
+	// rollout was last resumed. Only failures and responses since then count
+	// towards the failure rate of the rollout.
+	BaselineFailures  int64 `db:"baseline_failures" json:"-"`
+	BaselineResponses int64 `db:"baseline_responses" json:"-"`
+
+	// The number of tasks scheduled for the devices in the current stage, and
+	// the number of responses in the consignment when the current stage
+	// started. The rollout only advances once enough of the tasks in the
+	// current stage have been responded to.
+	StageTasks     int   `db:"stage_tasks" json:"stage_tasks"`
+	StageResponses int64 `db:"stage_responses" json:"-"`
+
+	// The time at which the rollout was created.
+	CreateTime time.Time `db:"create_time" json:"create_time"`
+}
+
+// Initialize and pre-create database statements to interact with the rollouts
+// table in the scheduler database.
+func createRolloutStatements() {
+	rolloutMetadata = table.Metadata{
+		Name: "rollouts",
+		Columns: []string{
+			"tenant_id",
+			"consignment_id",
+			"request",
+			"stage",
+			"status",
+			"next_stage_at",
+			"baseline_failures",
+			"baseline_responses",
+			"stage_tasks",
+			"stage_responses",
+			"create_time",
+		},
+		PartKey: []string{
+			"tenant_id",
+			"consignment_id",
+		},
+	}
+
+	rolloutsTable = table.New(rolloutMetadata)
+
+	// Store pre-created CQL query statements to interact with the rollouts
+	// table.
+	deleteStatement, deleteNames := rolloutsTable.Delete()
+	insertStatement, insertNames := rolloutsTable.Insert()
+	getStatement, getNames := qb.Select(rolloutMetadata.Name).
+		Columns(rolloutMetadata.Columns...).ToCql()
+
+	rolloutsStatements = &statements{
+		delete: query{
+			statement: deleteStatement,
+			names:     deleteNames,
+		},
+		insert: query{
+			statement: insertStatement,
+			names:     insertNames,
+		},
+		get: query{
+			statement: getStatement,
+			names:     getNames,
+		},
+	}
+}
@@ -20,8 +20,12 @@ var (
 
 // Represents the scheduled run of a task submitted for execution to the scheduler.
 type ScheduledRun struct {
+	// The time bucket in which the run is scheduled.
 	RunPartition string `db:"run_partition" json:"run_partition"`
 
+	// The shard within the time bucket to which the run is assigned.
+	Shard int `db:"shard" json:"shard"`
+
 	// Unique identifier assigned to a task.
 	TaskID gocql.UUID `db:"task_id" json:"task_id"`
 
@@ -33,6 +37,9 @@ type ScheduledRun struct {
 
 	// datetime of last run
 	LastRun time.Time `db:"last_run" json:"last_run"`
+
+	// If this run retries a failed attempt of the task.
+	IsRetry bool `db:"is_retry" json:"is_retry,omitempty"`
 }
 
 func NewScheduledRun(task *Task) *ScheduledRun {
@@ -46,19 +53,23 @@ func NewScheduledRun(task *Task) *ScheduledRun {
 func createScheduledRunStatements() {
 	// Metadata describing the schedules table in the scheduler database.
 	scheduledRunMetadata = table.Metadata{
-		Name: "scheduled_runs",
+		Name: "scheduled_run_buckets",
 		Columns: []string{
 			"run_partition",
+			"shard",
 			"next_run",
-			"last_run",
 			"task_id",
+			"last_run",
 			"device_id",
+			"is_retry",
 		},
 		PartKey: []string{
 			"run_partition",
+			"shard",
 		},
 		SortKey: []string{
 			"next_run",
+			"task_id",
 		},
 	}
 
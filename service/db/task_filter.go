@@ -0,0 +1,75 @@
+package db
+
+import (
+	"time"
+
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// Criteria used to filter the tasks returned by queries. Criteria which are not
+// specified match all tasks.
+type TaskFilter struct {
+	// Only return tasks with this status.
+	Status string
+
+	// Only return tasks for this device.
+	DeviceID string
+
+	// Only return tasks belonging to this tenant.
+	TenantID string
+
+	// Only return tasks with this message type.
+	MessageType string
+
+	// Only return tasks created at or after this time.
+	CreatedAfter time.Time
+
+	// Only return tasks created at or before this time.
+	CreatedBefore time.Time
+}
+
+// Add the filter criteria to the specified query of a table clustered by task
+// ID, along with the values to be bound to the query. Task IDs are time based
+// UUIDs issued when tasks are created, so the creation time range is applied
+// to the task ID.
+func (f *TaskFilter) apply(builder *qb.SelectBuilder, values qb.M) {
+	if f == nil {
+		return
+	}
+
+	if !f.CreatedAfter.IsZero() {
+		builder.Where(qb.GtOrEqFunc("task_id", qb.MinTimeuuid("created_after")))
+		values["created_after"] = f.CreatedAfter
+	}
+	if !f.CreatedBefore.IsZero() {
+		builder.Where(qb.LtOrEqFunc("task_id", qb.MaxTimeuuid("created_before")))
+		values["created_before"] = f.CreatedBefore
+	}
+
+	// Filtering on columns which are not part of the primary key is restricted
+	// to the partition being queried.
+	filtering := false
+	if f.Status != "" {
+		builder.Where(qb.Eq("status"))
+		values["status"] = f.Status
+		filtering = true
+	}
+	if f.DeviceID != "" {
+		builder.Where(qb.Eq("device_id"))
+		values["device_id"] = f.DeviceID
+		filtering = true
+	}
+	if f.TenantID != "" {
+		builder.Where(qb.Eq("tenant_id"))
+		values["tenant_id"] = f.TenantID
+		filtering = true
+	}
+	if f.MessageType != "" {
+		builder.Where(qb.Eq("message_type"))
+		values["message_type"] = f.MessageType
+		filtering = true
+	}
+	if filtering {
+		builder.AllowFiltering()
+	}
+}
@@ -33,6 +33,10 @@ const (
 	TaskStatusFailed
 	TaskStatusPendingRetry
 	TaskStatusUnknown
+	TaskStatusTimedOut
+	TaskStatusCancelled
+	TaskStatusPaused
+	TaskStatusScheduleExhausted
 )
 
 const (
@@ -42,6 +46,11 @@ const (
 	taskStatusFailed       = "failed"
 	taskStatusPendingRetry = "pending retry"
 	taskStatusUnknown      = "unknown"
+	taskStatusTimedOut     = "timed out"
+	taskStatusCancelled    = "cancelled"
+	taskStatusPaused       = "paused"
+
+	taskStatusScheduleExhausted = "schedule exhausted"
 )
 
 var taskStatusMap = map[TaskStatus]string{
@@ -51,6 +60,22 @@ var taskStatusMap = map[TaskStatus]string{
 	TaskStatusFailed:       taskStatusFailed,
 	TaskStatusPendingRetry: taskStatusPendingRetry,
 	TaskStatusUnknown:      taskStatusUnknown,
+	TaskStatusTimedOut:     taskStatusTimedOut,
+	TaskStatusCancelled:    taskStatusCancelled,
+	TaskStatusPaused:       taskStatusPaused,
+
+	TaskStatusScheduleExhausted: taskStatusScheduleExhausted,
+}
+
+// Task statuses which may be changed by the outcome of a run of the task.
+var runTaskStatuses = []string{
+	taskStatusQueued,
+	taskStatusDispatched,
+	taskStatusCompleted,
+	taskStatusFailed,
+	taskStatusPendingRetry,
+	taskStatusUnknown,
+	taskStatusTimedOut,
 }
 
 func (s TaskStatus) String() string {
@@ -61,6 +86,26 @@ func (s TaskStatus) String() string {
 	return val
 }
 
+// Check if the specified string is the name of a task status.
+func IsValidTaskStatus(status string) bool {
+	for _, val := range taskStatusMap {
+		if val == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the task status with the specified name.
+func parseTaskStatus(status string) TaskStatus {
+	for key, val := range taskStatusMap {
+		if val == status {
+			return key
+		}
+	}
+	return TaskStatusUnknown
+}
+
 // Represents a task stored in the scheduler database.
 type Task struct {
 	// Unique identifier assigned to a task.
@@ -122,9 +167,83 @@ type Task struct {
 	// optional time at which the task starts
 	StartAt time.Time `db:"start_at" json:"start_at,omitempty"`
 
+	// Optional time after which the task is no longer run.
+	EndAt time.Time `db:"end_at" json:"end_at,omitempty"`
+
+	// Optional maximum number of runs of the task.
+	MaxRuns int `db:"max_runs" json:"max_runs,omitempty"`
+
+	// Optional window following each scheduled time within which the run of
+	// the task is spread, at an offset derived from the device ID.
+	JitterWindow time.Duration `db:"jitter_window" json:"jitter_window,omitempty"`
+
+	// The number of runs of the task that have been dispatched.
+	RunCount int `db:"run_count" json:"run_count"`
+
 	// If the task can be run immediately without delay.
 	StartImmediately bool `db:"immediate" json:"immediate,omitempty"`
 
+	// The cron expression, including the CRON_TZ= location, for tasks that
+	// are scheduled using a crontab schedule.
+	CronSpec string `db:"cron_spec" json:"cron_spec,omitempty"`
+
+	// If the cron expression includes a seconds field.
+	CronWithSeconds bool `db:"cron_with_seconds" json:"cron_with_seconds,omitempty"`
+
+	// How runs of the task which were missed are handled, e.g. fire_once,
+	// fire_all, skip or "fire_if_within 15m". If empty, missed runs are run
+	// once.
+	MisfirePolicy string `db:"misfire_policy" json:"misfire_policy,omitempty"`
+
+	// The IANA timezone in which the schedule of the task is interpreted. If
+	// empty, the schedule is interpreted in UTC.
+	Timezone string `db:"timezone" json:"timezone,omitempty"`
+
+	// The iCalendar (RFC 5545) DTSTART, RRULE and EXDATE content lines, one
+	// per line, for tasks that are scheduled using a recurrence rule.
+	RRuleSpec string `db:"rrule_spec" json:"rrule_spec,omitempty"`
+
+	// The maximum number of attempts to execute the task as per the retry
+	// policy requested for the task. If zero, the retry policy configured for
+	// the service which requested the task is used.
+	RetryMaxAttempts int `db:"retry_max_attempts" json:"retry_max_attempts,omitempty"`
+
+	// The delay before the first retry of the task.
+	RetryInitialBackoff time.Duration `db:"retry_initial_backoff" json:"retry_initial_backoff,omitempty"`
+
+	// The maximum delay between retries of the task.
+	RetryMaxBackoff time.Duration `db:"retry_max_backoff" json:"retry_max_backoff,omitempty"`
+
+	// The factor by which the delay is multiplied after each retry.
+	RetryBackoffMultiplier float64 `db:"retry_backoff_multiplier" json:"retry_backoff_multiplier,omitempty"`
+
+	// The task statuses for which the task is retried.
+	RetryableStatuses []string `db:"retry_statuses" json:"retry_statuses,omitempty"`
+
+	// The time within which the device must respond to the task once it has
+	// been dispatched. If zero, the scheduler waits indefinitely.
+	ResponseTimeout time.Duration `db:"response_timeout" json:"response_timeout,omitempty"`
+
+	// The deadline for the response to the most recent dispatch of the task.
+	ResponseDeadline time.Time `db:"response_deadline" json:"response_deadline,omitempty"`
+
+	// The time at which the most recently dispatched run of the task was
+	// scheduled.
+	CurrentRun time.Time `db:"current_run" json:"current_run,omitempty"`
+
+	// The time of the next scheduled run of the task.
+	NextRun time.Time `db:"next_run" json:"next_run,omitempty"`
+
+	// The time of the pending retry of the task, if any.
+	RetryRun time.Time `db:"retry_run" json:"retry_run,omitempty"`
+
+	// The time at which the task was paused, if it is paused.
+	PausedAt time.Time `db:"paused_at" json:"paused_at,omitempty"`
+
+	// The status of the task at the time it was paused. The task returns to
+	// this status when it is resumed.
+	PausedStatus string `db:"paused_status" json:"-"`
+
 	// Identifier assigned to the message by the device management service.
 	MessageId string `db:"message_id" json:"message_id,omitempty"`
 
@@ -137,6 +256,18 @@ type Task struct {
 	TaskDetails []byte `db:"task_details" json:"task_details"`
 }
 
+// Check if the schedule of the task is bounded by an end time or a maximum
+// number of runs.
+func (task *Task) HasBoundedSchedule() bool {
+	return !task.EndAt.IsZero() || task.MaxRuns > 0
+}
+
+// Check if the task has reached the end of its bounded schedule, in which case
+// no further runs of the task are scheduled.
+func (task *Task) IsScheduleEnded() bool {
+	return task.HasBoundedSchedule() && task.NextRun.IsZero()
+}
+
 func NewTask(tenantID *string, deviceID *string, consignmentID *string,
 	taskDetails *[]byte) (*Task, error) {
 	newTask := Task{
@@ -150,7 +281,7 @@ func NewTask(tenantID *string, deviceID *string, consignmentID *string,
 }
 
 func (task *Task) MarshalServiceMessage() (*string, error) {
-	msg := &pb.ServiceMessage{
+	return task.marshalServiceMessage(&pb.ServiceMessage{
 		Version:     1,
 		ServiceId:   task.ServiceID,
 		DeviceId:    task.DeviceID.String(),
@@ -159,8 +290,23 @@ func (task *Task) MarshalServiceMessage() (*string, error) {
 		MessageId:   task.MessageId,
 		MessageType: task.MessageType,
 		Payload:     task.TaskDetails,
-	}
+	})
+}
+
+// Encode a message asking the device to cancel the task.
+func (task *Task) MarshalCancelMessage() (*string, error) {
+	return task.marshalServiceMessage(&pb.ServiceMessage{
+		Version:     1,
+		ServiceId:   task.ServiceID,
+		DeviceId:    task.DeviceID.String(),
+		TaskId:      task.TaskID.String(),
+		TenantId:    task.TenantID,
+		MessageId:   task.MessageId,
+		MessageType: common.CancelTaskMessageType,
+	})
+}
 
+func (task *Task) marshalServiceMessage(msg *pb.ServiceMessage) (*string, error) {
 	payload, err := proto.Marshal(msg)
 	if err != nil {
 		schedLogger.Error("Failed to encode the message for delivery to the device!",
@@ -244,7 +390,28 @@ func createTaskStatements() {
 			"week_days",
 			"month_days",
 			"start_at",
+			"end_at",
+			"max_runs",
+			"run_count",
 			"immediate",
+			"cron_spec",
+			"cron_with_seconds",
+			"rrule_spec",
+			"timezone",
+			"misfire_policy",
+			"jitter_window",
+			"retry_max_attempts",
+			"retry_initial_backoff",
+			"retry_max_backoff",
+			"retry_backoff_multiplier",
+			"retry_statuses",
+			"response_timeout",
+			"response_deadline",
+			"current_run",
+			"next_run",
+			"retry_run",
+			"paused_at",
+			"paused_status",
 			"message_id",
 			"message_type",
 			"task_details",
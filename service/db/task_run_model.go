@@ -0,0 +1,114 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/scylladb/gocqlx/v2/qb"
+	"github.com/scylladb/gocqlx/v2/table"
+)
+
+var (
+	// Metadata describing the task runs table in the scheduler database.
+	taskRunMetadata table.Metadata
+
+	taskRunsTable *table.Table
+
+	// Pre-created CQL query statements to interact with the task runs table.
+	taskRunsStatements *statements
+)
+
+// Decisions recorded in the run history for runs of a task which were missed,
+// as per the misfire policy of the task.
+const (
+	// The run was dispatched once in place of all the missed runs.
+	MisfireDecisionFiredOnce = "fired_once"
+
+	// The run was dispatched, and any further missed runs are also
+	// dispatched.
+	MisfireDecisionFired = "fired"
+
+	// The run was not dispatched.
+	MisfireDecisionSkipped = "skipped"
+)
+
+// Status of runs which were missed and not dispatched.
+const taskRunStatusSkipped = "skipped"
+
+// Represents a single run of a task stored in the scheduler database.
+type TaskRun struct {
+	// Unique identifier assigned to a task.
+	TaskID gocql.UUID `db:"task_id" json:"task_id"`
+
+	// The time at which the run was scheduled.
+	RunTime time.Time `db:"run_time" json:"run_time"`
+
+	// The unique identifier associated with the device.
+	DeviceID gocql.UUID `db:"device_id" json:"device_id"`
+
+	// The time at which the task was most recently sent to the device for
+	// this run.
+	DispatchTime time.Time `db:"dispatch_time" json:"dispatch_time,omitempty"`
+
+	// Status of the run, as reported by the device.
+	Status string `db:"status" json:"status"`
+
+	// Identifier assigned by the device to its response to the task.
+	MessageID string `db:"message_id" json:"message_id,omitempty"`
+
+	// The time at which the run ended.
+	EndTime time.Time `db:"end_time" json:"end_time,omitempty"`
+
+	// If the run was missed, how it was handled as per the misfire policy of
+	// the task.
+	MisfireDecision string `db:"misfire_decision" json:"misfire_decision,omitempty"`
+}
+
+// Initialize and pre-create database statements to interact with the task
+// runs table in the scheduler database.
+func createTaskRunStatements() {
+	// Metadata describing the task runs table in the scheduler database.
+	taskRunMetadata = table.Metadata{
+		Name: "task_runs",
+		Columns: []string{
+			"task_id",
+			"run_time",
+			"device_id",
+			"dispatch_time",
+			"status",
+			"message_id",
+			"end_time",
+			"misfire_decision",
+		},
+		PartKey: []string{
+			"task_id",
+		},
+		SortKey: []string{
+			"run_time",
+		},
+	}
+
+	taskRunsTable = table.New(taskRunMetadata)
+
+	// Store pre-created CQL query statements to interact with the task runs
+	// table.
+	deleteStatement, deleteNames := taskRunsTable.Delete()
+	insertStatement, insertNames := taskRunsTable.Insert()
+	getStatement, getNames := qb.Select(taskRunMetadata.Name).
+		Columns(taskRunMetadata.Columns...).ToCql()
+
+	taskRunsStatements = &statements{
+		delete: query{
+			statement: deleteStatement,
+			names:     deleteNames,
+		},
+		insert: query{
+			statement: insertStatement,
+			names:     insertNames,
+		},
+		get: query{
+			statement: getStatement,
+			names:     getNames,
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+// Update the per-status task counts for the consignment when the status of one
+// of its tasks changes from the previous status to the new status, and record
+// the time of the activity. An empty previous status denotes a new task and an
+// empty new status denotes a removed task.
+func updateConsignmentSummary(tenantID string, consignmentID string,
+	prevStatus string, newStatus string) error {
+
+	gSessionMutex.RLock()
+	defer gSessionMutex.RUnlock()
+
+	if prevStatus != newStatus {
+		batch := gSession.Session.NewBatch(gocql.CounterBatch)
+		if prevStatus != "" {
+			batch.Query(`UPDATE consignment_status_counts SET task_count = task_count - 1 WHERE tenant_id=? AND consignment_id=? AND status=?`,
+				tenantID, consignmentID, prevStatus)
+		}
+		if newStatus != "" {
+			batch.Query(`UPDATE consignment_status_counts SET task_count = task_count + 1 WHERE tenant_id=? AND consignment_id=? AND status=?`,
+				tenantID, consignmentID, newStatus)
+		}
+		err := gSession.Session.ExecuteBatch(batch)
+		if err != nil {
+			schedLogger.Error("Failed to update the task status counts for the consignment!",
+				zap.String("Tenant ID:", tenantID),
+				zap.String("Consignment ID:", consignmentID),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+
+	err := gSession.Session.Query(`UPDATE consignment_activity SET last_activity=? WHERE tenant_id=? AND consignment_id=?`,
+		time.Now(), tenantID, consignmentID).Exec()
+	if err != nil {
+		schedLogger.Error("Failed to record activity for the consignment!",
+			zap.String("Tenant ID:", tenantID),
+			zap.String("Consignment ID:", consignmentID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
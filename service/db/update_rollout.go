@@ -0,0 +1,93 @@
+package db
+
+import (
+	"github.com/gocql/gocql"
+	"go.uber.org/zap"
+)
+
+// Update the stage, status, time of the next stage, failure baselines and
+// progress of the current stage of the rollout, provided that it is still at
+// the specified stage with the specified status. Returns false if the rollout
+// has since changed, for instance because it was advanced or paused
+// concurrently.
+func (r *Rollout) UpdateRollout(stage int, status string) (bool, error) {
+	gSessionMutex.RLock()
+	applied, err := gSession.Session.Query(`UPDATE rollouts SET stage=?, status=?, next_stage_at=?, baseline_failures=?, baseline_responses=?, stage_tasks=?, stage_responses=? WHERE tenant_id=? AND consignment_id=? IF stage=? AND status=?`,
+		r.Stage, r.Status, r.NextStageAt, r.BaselineFailures,
+		r.BaselineResponses, r.StageTasks, r.StageResponses, r.TenantID,
+		r.ConsignmentID, stage, status).MapScanCAS(map[string]interface{}{})
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to update the rollout in the scheduler database!",
+			zap.String("Tenant ID:", r.TenantID),
+			zap.String("Consignment ID:", r.ConsignmentID),
+			zap.Error(err),
+		)
+		return false, err
+	}
+
+	return applied, nil
+}
+
+// Remove the devices of the rollout from the scheduler database, once tasks
+// have been scheduled for every device or the rollout has been cancelled.
+func (r *Rollout) RemoveRolloutDevices() error {
+	gSessionMutex.RLock()
+	err := gSession.Session.Query(`DELETE FROM rollout_devices WHERE tenant_id=? AND consignment_id=?`,
+		r.TenantID, r.ConsignmentID).Exec()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to remove the devices of the rollout from the scheduler database!",
+			zap.String("Tenant ID:", r.TenantID),
+			zap.String("Consignment ID:", r.ConsignmentID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Remove the device in the specified stage of the rollout from the scheduler
+// database, once a task has been scheduled for it.
+func (r *Rollout) RemoveRolloutDevice(stage int, deviceID string) error {
+	gSessionMutex.RLock()
+	err := gSession.Session.Query(`DELETE FROM rollout_devices WHERE tenant_id=? AND consignment_id=? AND stage=? AND device_id=?`,
+		r.TenantID, r.ConsignmentID, stage, deviceID).Exec()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to remove a device of the rollout from the scheduler database!",
+			zap.String("Tenant ID:", r.TenantID),
+			zap.String("Consignment ID:", r.ConsignmentID),
+			zap.Int("Stage:", stage),
+			zap.String("Device ID:", deviceID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Remove the rollout and its devices from the scheduler database within a
+// single logged batch, when the rollout could not be started.
+func (r *Rollout) RemoveRollout() error {
+	gSessionMutex.RLock()
+	batch := gSession.Session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM rollout_devices WHERE tenant_id=? AND consignment_id=?`,
+		r.TenantID, r.ConsignmentID)
+	batch.Query(`DELETE FROM rollouts WHERE tenant_id=? AND consignment_id=?`,
+		r.TenantID, r.ConsignmentID)
+	err := gSession.Session.ExecuteBatch(batch)
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to remove the rollout from the scheduler database!",
+			zap.String("Tenant ID:", r.TenantID),
+			zap.String("Consignment ID:", r.ConsignmentID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
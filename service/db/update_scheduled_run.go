@@ -0,0 +1,48 @@
+package db
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reschedule the scheduled run to the specified time. The run partition is
+// derived from the time of the next run, so a new row is inserted in the
+// (possibly different) partition for the new time and the time of the next run
+// is recorded with the task, before the existing row is removed. A row left
+// behind by a failure along the way does not match the next run of the task
+// and is dropped by the scheduler daemon. If the task has been removed
+// concurrently, the new row is removed again and ErrNotFound is returned.
+func (s *ScheduledRun) Reschedule(nextRun time.Time) error {
+	newRun := ScheduledRun{
+		RunPartition: GetRunPartition(nextRun),
+		Shard:        GetRunShard(s.TaskID),
+		TaskID:       s.TaskID,
+		DeviceID:     s.DeviceID,
+		NextRun:      nextRun,
+		LastRun:      s.NextRun,
+	}
+
+	gSessionMutex.RLock()
+	err := gSession.Session.Query(`INSERT INTO scheduled_run_buckets (run_partition, shard, next_run, task_id, last_run, device_id) VALUES (?, ?, ?, ?, ?, ?)`,
+		newRun.RunPartition, newRun.Shard, newRun.NextRun, newRun.TaskID,
+		newRun.LastRun, newRun.DeviceID).Exec()
+	gSessionMutex.RUnlock()
+	if err == nil {
+		err = newRun.recordWithTask()
+	}
+	if err == nil {
+		err = s.deleteRunEntry()
+	}
+	if err != nil {
+		schedLogger.Error("Failed to reschedule the scheduled run in the scheduler database!",
+			zap.String("Task ID:", s.TaskID.String()),
+			zap.Time("Next Run:", nextRun),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	*s = newRun
+	return nil
+}
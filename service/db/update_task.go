@@ -1,7 +1,10 @@
 package db
 
 import (
+	"time"
+
 	"github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/common"
 	"go.uber.org/zap"
 )
 
@@ -31,30 +34,281 @@ func UpdateTaskStatus(taskID string, deviceID string, tenantID string,
 	return nil
 }
 
+// Set the status of the task. The status of cancelled, paused and exhausted
+// tasks is never changed, so that late responses from the device do not
+// overwrite the cancellation or pause of the task, or the end of its schedule.
 func setTaskStatus(taskID string, deviceID string, status TaskStatus) error {
+	return updateTaskIfStatus(deviceID, taskID, runTaskStatuses, "status=?",
+		status.String())
+}
+
+// Update the task with the specified CQL assignments, provided that the status
+// of the task is one of the specified statuses. Status transitions of tasks are
+// always made with conditional updates, so that they are applied in order with
+// respect to each other. Returns an error describing the status of the task if
+// the update was not applied.
+func updateTaskIfStatus(deviceID interface{}, taskID interface{},
+	statuses []string, assignments string, values ...interface{}) error {
+	applied, previous, err := updateTaskIf(deviceID, taskID, assignments,
+		values, "status IN ?", statuses)
+	if err != nil {
+		return err
+	}
+
+	if !applied {
+		return taskStatusError(previous["status"])
+	}
+	return nil
+}
+
+// Return the error reported when a conditional update of a task in the
+// specified status was not applied.
+func taskStatusError(status interface{}) error {
+	switch status {
+	case nil:
+		return ErrNotFound
+	case TaskStatusCancelled.String():
+		return ErrTaskCancelled
+	case TaskStatusPaused.String():
+		return ErrTaskPaused
+	case TaskStatusScheduleExhausted.String():
+		return ErrScheduleExhausted
+	}
+	return ErrTaskStatusChanged
+}
+
+// Conditionally update the task with the specified CQL assignments, provided
+// that the specified CQL condition holds. Returns whether the update was
+// applied and, if not, the current values of the columns in the condition.
+func updateTaskIf(deviceID interface{}, taskID interface{}, assignments string,
+	values []interface{}, condition string,
+	conditionValues ...interface{}) (bool, map[string]interface{}, error) {
+	previous := map[string]interface{}{}
+
+	args := append([]interface{}{}, values...)
+	args = append(args, deviceID, taskID)
+	args = append(args, conditionValues...)
 	gSessionMutex.RLock()
-	err := gSession.Session.Query(`UPDATE tasks SET status=? WHERE device_id=? AND task_id=?`,
-		status.String(), deviceID, taskID).Exec()
+	applied, err := gSession.Session.Query(`UPDATE tasks SET `+assignments+
+		` WHERE device_id=? AND task_id=? IF `+condition,
+		args...).MapScanCAS(previous)
 	gSessionMutex.RUnlock()
+	return applied, previous, err
+}
+
+// Check if the message for the specified task should still be sent to the
+// device. Messages for tasks that have since been removed, cancelled or paused
+// are dropped. Messages asking the device to cancel a task are always sent.
+func ShouldDispatchTask(taskinfo *protos.ServiceMessage) (bool, error) {
+	if taskinfo.MessageType == common.CancelTaskMessageType {
+		return true, nil
+	}
+
+	task, err := GetTaskByID(taskinfo.TaskId, taskinfo.DeviceId)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
 
-	return err
+	return task.Status != TaskStatusCancelled.String() &&
+		task.Status != TaskStatusPaused.String(), nil
 }
 
+// Mark the task and its current run as dispatched. If the device is expected
+// to respond to the task within a response timeout, the deadline for the
+// response is recorded.
 func MarkTaskDispatched(taskinfo *protos.ServiceMessage) error {
 	if (taskinfo.DeviceId == "") || (taskinfo.TaskId == "") {
 		schedLogger.Error("Invalid device ID or task ID specified!")
 		return ErrInvalidRequest
 	}
 
-	return setTaskStatus(taskinfo.TaskId, taskinfo.DeviceId, TaskStatusDispatched)
+	// Messages asking the device to cancel a task do not change the status
+	// of the task.
+	if taskinfo.MessageType == common.CancelTaskMessageType {
+		return nil
+	}
+
+	task, err := GetTaskByID(taskinfo.TaskId, taskinfo.DeviceId)
+	if err != nil {
+		return err
+	}
+
+	if task.Status == TaskStatusCancelled.String() ||
+		task.Status == TaskStatusPaused.String() {
+		return nil
+	}
+
+	dispatchTime := time.Now()
+	err = markTaskRunDispatched(task, dispatchTime)
+	if err != nil {
+		return err
+	}
+
+	if task.ResponseTimeout <= 0 {
+		err = setTaskStatus(taskinfo.TaskId, taskinfo.DeviceId,
+			TaskStatusDispatched)
+	} else {
+		deadline := ResponseDeadline{
+			Deadline: dispatchTime.Add(task.ResponseTimeout),
+			TaskID:   task.TaskID,
+			DeviceID: task.DeviceID,
+		}
+		err = deadline.CreateResponseDeadline()
+		if err != nil {
+			return err
+		}
+
+		err = updateTaskIfStatus(task.DeviceID, task.TaskID, runTaskStatuses,
+			"status=?, response_deadline=?", TaskStatusDispatched.String(),
+			deadline.Deadline)
+	}
+
+	// Tasks which were cancelled or paused while being dispatched keep their
+	// status.
+	if err == ErrTaskCancelled || err == ErrTaskPaused {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return UpdateConsignmentTaskStatus(task.TenantID, task.ConsignmentID,
+		taskinfo.TaskId, TaskStatusDispatched)
 }
 
+// Mark the task as completed. Tasks which have reached the end of their
+// bounded schedule are marked as exhausted once their final run completes.
 func MarkTaskComplete(task *Task) error {
+	status := TaskStatusCompleted
+	if task.IsScheduleEnded() {
+		status = TaskStatusScheduleExhausted
+	}
 	return UpdateTaskStatus(task.TaskID.String(), task.DeviceID.String(), task.TenantID,
-		task.ConsignmentID, TaskStatusCompleted)
+		task.ConsignmentID, status)
+}
+
+// Mark the task as having exhausted its bounded schedule, when it has no run
+// left to complete.
+func MarkTaskScheduleExhausted(task *Task) error {
+	return UpdateTaskStatus(task.TaskID.String(), task.DeviceID.String(), task.TenantID,
+		task.ConsignmentID, TaskStatusScheduleExhausted)
+}
+
+// Mark the task as pending a retry and increment the number of times the task
+// has been retried.
+func MarkTaskPendingRetry(task *Task) error {
+	err := updateTaskIfStatus(task.DeviceID, task.TaskID, runTaskStatuses,
+		"status=?, retry_count=?", TaskStatusPendingRetry.String(),
+		task.RetryCount+1)
+	if err != nil {
+		schedLogger.Error("Failed to mark the task as pending retry!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.String("Device ID:", task.DeviceID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+	task.RetryCount++
+	task.Status = TaskStatusPendingRetry.String()
+
+	return UpdateConsignmentTaskStatus(task.TenantID, task.ConsignmentID,
+		task.TaskID.String(), TaskStatusPendingRetry)
 }
 
 func MarkTaskFailed(task *Task) error {
 	return UpdateTaskStatus(task.TaskID.String(), task.DeviceID.String(), task.TenantID,
 		task.ConsignmentID, TaskStatusFailed)
 }
+
+func MarkTaskTimedOut(task *Task) error {
+	return UpdateTaskStatus(task.TaskID.String(), task.DeviceID.String(), task.TenantID,
+		task.ConsignmentID, TaskStatusTimedOut)
+}
+
+// Update the schedule, message type and payload of the task, provided that
+// neither its status nor its next run has changed since it was read, otherwise
+// ErrTaskStatusChanged is returned. Checking the next run ensures that a run
+// scheduled concurrently by the scheduler daemon is not left behind alongside
+// the run of the updated schedule. If the time of the next run is specified, the schedule of the task
+// has changed and its scheduled run is replaced by a run at the specified time.
+// The new run is created before the task is updated and removed again if the
+// update is not applied.
+func UpdateTaskDefinition(task *Task, nextRun time.Time) error {
+	newRun := ScheduledRun{
+		RunPartition: GetRunPartition(nextRun),
+		Shard:        GetRunShard(task.TaskID),
+		TaskID:       task.TaskID,
+		DeviceID:     task.DeviceID,
+		NextRun:      nextRun,
+		LastRun:      task.CurrentRun,
+	}
+
+	assignments := "message_type=?, task_details=?, unit=?, interval=?, duration=?, run_at=?, week_days=?, month_days=?, start_at=?, end_at=?, max_runs=?, jitter_window=?, immediate=?, cron_spec=?, cron_with_seconds=?, rrule_spec=?"
+	values := []interface{}{task.MessageType, task.TaskDetails, task.Unit,
+		task.Interval, task.Duration, task.RunAt, task.ScheduledWeekdays,
+		task.ScheduledDaysOfTheMonth, task.StartAt, task.EndAt, task.MaxRuns,
+		task.JitterWindow, task.StartImmediately, task.CronSpec,
+		task.CronWithSeconds, task.RRuleSpec}
+
+	var err error
+	if !nextRun.IsZero() {
+		assignments += ", next_run=?"
+		values = append(values, nextRun)
+
+		gSessionMutex.RLock()
+		err = gSession.Session.Query(`INSERT INTO scheduled_run_buckets (run_partition, shard, next_run, task_id, last_run, device_id) VALUES (?, ?, ?, ?, ?, ?)`,
+			newRun.RunPartition, newRun.Shard, newRun.NextRun, newRun.TaskID,
+			newRun.LastRun, newRun.DeviceID).Exec()
+		gSessionMutex.RUnlock()
+	}
+	if err == nil {
+		condition := "status IN ? AND next_run=?"
+		conditionValues := []interface{}{[]string{task.Status}, task.NextRun}
+		if task.NextRun.IsZero() {
+			condition = "status IN ? AND next_run=null"
+			conditionValues = conditionValues[:1]
+		}
+
+		var (
+			applied  bool
+			previous map[string]interface{}
+		)
+		applied, previous, err = updateTaskIf(task.DeviceID, task.TaskID,
+			assignments, values, condition, conditionValues...)
+		if err == nil && !applied {
+			err = taskStatusError(previous["status"])
+			if previous["status"] == task.Status {
+				err = ErrTaskStatusChanged
+			}
+		}
+		if err != nil && !nextRun.IsZero() {
+			_ = newRun.deleteRunEntry()
+		}
+	}
+	if err == nil && !nextRun.IsZero() && !task.NextRun.IsZero() &&
+		!task.NextRun.Equal(nextRun) {
+		oldRun := ScheduledRun{
+			RunPartition: GetRunPartition(task.NextRun),
+			Shard:        GetRunShard(task.TaskID),
+			TaskID:       task.TaskID,
+			NextRun:      task.NextRun,
+		}
+		err = oldRun.deleteRunEntry()
+	}
+	if err != nil {
+		schedLogger.Error("Failed to update the task!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.String("Device ID:", task.DeviceID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if !nextRun.IsZero() {
+		task.NextRun = nextRun
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Record the dispatch of the current run of the task to the device.
+func markTaskRunDispatched(task *Task, dispatchTime time.Time) error {
+	if task.CurrentRun.IsZero() {
+		return nil
+	}
+
+	gSessionMutex.RLock()
+	err := gSession.Session.Query(`UPDATE task_runs SET dispatch_time=?, status=? WHERE task_id=? AND run_time=?`,
+		dispatchTime, TaskStatusDispatched.String(), task.TaskID,
+		task.CurrentRun).Exec()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to mark the task run as dispatched!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.Time("Run Time:", task.CurrentRun),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Record the outcome of the current run of the task, along with the ID of the
+// message in which the device responded to the task, if any.
+func EndTaskRun(task *Task, status string, messageID string) error {
+	if task.CurrentRun.IsZero() {
+		return nil
+	}
+
+	gSessionMutex.RLock()
+	err := gSession.Session.Query(`UPDATE task_runs SET status=?, message_id=?, end_time=? WHERE task_id=? AND run_time=?`,
+		status, messageID, time.Now(), task.TaskID, task.CurrentRun).Exec()
+	gSessionMutex.RUnlock()
+	if err != nil {
+		schedLogger.Error("Failed to record the outcome of the task run!",
+			zap.String("Task ID:", task.TaskID.String()),
+			zap.Time("Run Time:", task.CurrentRun),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -78,7 +78,8 @@ func main() {
 	}
 
 	// Initialize the queue manager.
-	err = queuemgr.Init(schedLogger, cfgMgr, scheduler.ScheduleRequestHandlerFunc)
+	err = queuemgr.Init(schedLogger, cfgMgr, scheduler.ScheduleRequestHandlerFunc,
+		scheduler.DispatchFailureHandlerFunc)
 	if err != nil {
 		schedLogger.Error("Failed to initialize the queue manager!",
 			zap.Error(err),
@@ -9,6 +9,10 @@ import (
 // RegisterPrometheusMetrics - register prometheus metrics.
 func RegisterPrometheusMetrics() {
 	prometheus.MustRegister(MetricRestLatency)
+	prometheus.MustRegister(MetricRunDispatchLateness)
+	prometheus.MustRegister(MetricNearTermRunsQueued)
+	prometheus.MustRegister(MetricRolloutStagesAdvanced)
+	prometheus.MustRegister(MetricRolloutsPaused)
 }
 
 func ReportLatencyMetric(metric *prometheus.SummaryVec,
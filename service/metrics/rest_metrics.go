@@ -49,6 +49,79 @@ var (
 			Help: "Total number of successful remove task requests to the scheduler",
 		})
 
+	// Number of list task runs requests processed successfully by the scheduler.
+	MetricListTaskRunsResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_task_runs_listed",
+			Help: "Total number of successful list task runs requests to the scheduler",
+		})
+
+	// Number of cancel consignment requests processed successfully by the
+	// scheduler.
+	MetricCancelConsignmentResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_consignments_cancelled",
+			Help: "Total number of successful cancel consignment requests to the scheduler",
+		})
+
+	// Number of get consignment summary requests processed successfully by the
+	// scheduler.
+	MetricGetConsignmentSummaryResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_consignment_summaries_retrieved",
+			Help: "Total number of successful get consignment summary requests to the scheduler",
+		})
+
+	// Number of list device tasks requests processed successfully by the
+	// scheduler.
+	MetricListDeviceTasksResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_device_tasks_listed",
+			Help: "Total number of successful list device tasks requests to the scheduler",
+		})
+
+	// Number of update task requests processed successfully by the scheduler.
+	MetricUpdateTaskResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_tasks_updated",
+			Help: "Total number of successful update task requests to the scheduler",
+		})
+
+	// Number of pause task requests processed successfully by the scheduler.
+	MetricPauseTaskResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_tasks_paused",
+			Help: "Total number of successful pause task requests to the scheduler",
+		})
+
+	// Number of resume task requests processed successfully by the scheduler.
+	MetricResumeTaskResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_tasks_resumed",
+			Help: "Total number of successful resume task requests to the scheduler",
+		})
+
+	// Number of pause consignment requests processed successfully by the scheduler.
+	MetricPauseConsignmentResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_consignments_paused",
+			Help: "Total number of successful pause consignment requests to the scheduler",
+		})
+
+	// Number of resume consignment requests processed successfully by the scheduler.
+	MetricResumeConsignmentResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_consignments_resumed",
+			Help: "Total number of successful resume consignment requests to the scheduler",
+		})
+
+	// Number of preview schedule requests processed successfully by the scheduler.
+	MetricPreviewScheduleResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_schedules_previewed",
+			Help: "Total number of successful preview schedule requests to the scheduler",
+		})
+
 	// Number of bad/invalid create task requests to the scheduler.
 	MetricCreateTaskBadRequests = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -83,6 +156,118 @@ var (
 			Help: "Total number of bad remove task requests to the scheduler",
 		})
 
+	MetricRemoveTaskNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_remove_task_not_found",
+			Help: "Total number of remove task requests where the task was not found",
+		})
+
+	// Number of bad/invalid list task runs requests to the scheduler.
+	MetricListTaskRunsBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_list_task_runs_bad_requests",
+			Help: "Total number of bad list task runs requests to the scheduler",
+		})
+
+	MetricListTaskRunsNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_list_task_runs_not_found",
+			Help: "Total number of list task runs requests where the task was not found",
+		})
+
+	// Number of bad/invalid cancel consignment requests to the scheduler.
+	MetricCancelConsignmentBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_cancel_consignment_bad_requests",
+			Help: "Total number of bad cancel consignment requests to the scheduler",
+		})
+
+	MetricCancelConsignmentNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_cancel_consignment_not_found",
+			Help: "Total number of cancel consignment requests where the consignment was not found",
+		})
+
+	// Number of bad/invalid get consignment summary requests to the scheduler.
+	MetricGetConsignmentSummaryBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_get_consignment_summary_bad_requests",
+			Help: "Total number of bad get consignment summary requests to the scheduler",
+		})
+
+	MetricGetConsignmentSummaryNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_get_consignment_summary_not_found",
+			Help: "Total number of get consignment summary requests where the consignment was not found",
+		})
+
+	// Number of bad/invalid list device tasks requests to the scheduler.
+	MetricListDeviceTasksBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_list_device_tasks_bad_requests",
+			Help: "Total number of bad list device tasks requests to the scheduler",
+		})
+
+	// Number of bad/invalid update task requests to the scheduler.
+	MetricUpdateTaskBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_update_task_bad_requests",
+			Help: "Total number of bad update task requests to the scheduler",
+		})
+
+	MetricUpdateTaskNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_update_task_not_found",
+			Help: "Total number of update task requests where the task was not found",
+		})
+
+	// Number of bad/invalid pause task requests to the scheduler.
+	MetricPauseTaskBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_pause_task_bad_requests",
+			Help: "Total number of bad pause task requests to the scheduler",
+		})
+
+	MetricPauseTaskNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_pause_task_not_found",
+			Help: "Total number of pause task requests where the task was not found",
+		})
+
+	// Number of bad/invalid resume task requests to the scheduler.
+	MetricResumeTaskBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_resume_task_bad_requests",
+			Help: "Total number of bad resume task requests to the scheduler",
+		})
+
+	MetricResumeTaskNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_resume_task_not_found",
+			Help: "Total number of resume task requests where the task was not found",
+		})
+
+	// Number of bad/invalid pause consignment requests to the scheduler.
+	MetricPauseConsignmentBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_pause_consignment_bad_requests",
+			Help: "Total number of bad pause consignment requests to the scheduler",
+		})
+
+	// Number of bad/invalid resume consignment requests to the scheduler.
+	MetricResumeConsignmentBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_resume_consignment_bad_requests",
+			Help: "Total number of bad resume consignment requests to the scheduler",
+		})
+
+	// Number of bad/invalid preview schedule requests to the scheduler.
+	MetricPreviewScheduleBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_preview_schedule_bad_requests",
+			Help: "Total number of bad preview schedule requests to the scheduler",
+		})
+
 	// Number of create task requests to the scheduler resulting in internal errors.
 	MetricCreateTaskInternalErrors = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -110,4 +295,84 @@ var (
 			Name: "sched_rest_remove_task_internal_errors",
 			Help: "Total number of internal errors processing remove task requests",
 		})
+
+	// Number of list task runs requests to the scheduler resulting in internal
+	// errors.
+	MetricListTaskRunsInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_list_task_runs_internal_errors",
+			Help: "Total number of internal errors processing list task runs requests",
+		})
+
+	// Number of cancel consignment requests to the scheduler resulting in
+	// internal errors.
+	MetricCancelConsignmentInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_cancel_consignment_internal_errors",
+			Help: "Total number of internal errors processing cancel consignment requests",
+		})
+
+	// Number of get consignment summary requests to the scheduler resulting in
+	// internal errors.
+	MetricGetConsignmentSummaryInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_get_consignment_summary_internal_errors",
+			Help: "Total number of internal errors processing get consignment summary requests",
+		})
+
+	// Number of list device tasks requests to the scheduler resulting in
+	// internal errors.
+	MetricListDeviceTasksInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_list_device_tasks_internal_errors",
+			Help: "Total number of internal errors processing list device tasks requests",
+		})
+
+	// Number of update task requests to the scheduler resulting in internal
+	// errors.
+	MetricUpdateTaskInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_update_task_internal_errors",
+			Help: "Total number of internal errors processing update task requests",
+		})
+
+	// Number of pause task requests to the scheduler resulting in internal
+	// errors.
+	MetricPauseTaskInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_pause_task_internal_errors",
+			Help: "Total number of internal errors processing pause task requests",
+		})
+
+	// Number of resume task requests to the scheduler resulting in internal
+	// errors.
+	MetricResumeTaskInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_resume_task_internal_errors",
+			Help: "Total number of internal errors processing resume task requests",
+		})
+
+	// Number of pause consignment requests to the scheduler resulting in internal
+	// errors.
+	MetricPauseConsignmentInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_pause_consignment_internal_errors",
+			Help: "Total number of internal errors processing pause consignment requests",
+		})
+
+	// Number of resume consignment requests to the scheduler resulting in internal
+	// errors.
+	MetricResumeConsignmentInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_resume_consignment_internal_errors",
+			Help: "Total number of internal errors processing resume consignment requests",
+		})
+
+	// Number of preview schedule requests to the scheduler resulting in internal
+	// errors.
+	MetricPreviewScheduleInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rest_preview_schedule_internal_errors",
+			Help: "Total number of internal errors processing preview schedule requests",
+		})
 )
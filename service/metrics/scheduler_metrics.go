@@ -0,0 +1,36 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// How late scheduled runs are dispatched, relative to the time at which
+	// they were due.
+	MetricRunDispatchLateness = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "sched_run_dispatch_lateness_seconds",
+			Help:    "Delay between the time a scheduled run was due and the time it was dispatched",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 15, 30, 60, 300},
+		})
+
+	// Number of runs held in memory to be dispatched at their exact time
+	// before the next pass of the scheduler daemon.
+	MetricNearTermRunsQueued = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sched_near_term_runs_queued",
+			Help: "Number of runs queued to be dispatched before the next pass of the scheduler daemon",
+		})
+
+	// Number of times a rollout advanced to its next stage.
+	MetricRolloutStagesAdvanced = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rollout_stages_advanced",
+			Help: "Number of times a rollout advanced to its next stage",
+		})
+
+	// Number of rollouts paused because too many tasks failed.
+	MetricRolloutsPaused = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sched_rollouts_paused",
+			Help: "Number of rollouts paused because the failure rate of the tasks exceeded the threshold",
+		})
+)
@@ -17,6 +17,9 @@ const (
 	// managed devices.
 	taskResponsesTopicSubscription  = "$share/krypton/v1/@cloud/task_responses"
 	serviceMessageTopicSubscription = "$share/krypton/v1/@cloud"
+
+	// Topic on which devices send responses for tasks.
+	TaskResponsesTopic = "v1/@cloud/task_responses"
 )
 
 // A map of topics to which the scheduler subscribes to.
@@ -17,7 +17,8 @@ var (
 )
 
 func Init(logger *zap.Logger, cfgMgr *config.ConfigMgr,
-	inputEventHandler common.InputEventHandlerFunc) error {
+	inputEventHandler common.InputEventHandlerFunc,
+	dispatchFailureHandler common.DispatchFailureHandlerFunc) error {
 	schedLogger = logger
 
 	// Initialize the AWS SQS queue provider. For now, we only have a single
@@ -39,7 +40,7 @@ func Init(logger *zap.Logger, cfgMgr *config.ConfigMgr,
 
 	// Watch the scheduler dispatch queue for tasks to be dispatched to the
 	// MQTT broker for delivery to devices.
-	go Provider.WatchDispatchQueue()
+	go Provider.WatchDispatchQueue(dispatchFailureHandler)
 
 	return nil
 }
@@ -16,8 +16,9 @@ type QueueProvider interface {
 	WatchInputQueue(handler common.InputEventHandlerFunc)
 
 	// Watch the scheduler dispatch queue for tasks to be dispatched to the
-	// MQTT broker.
-	WatchDispatchQueue()
+	// MQTT broker. Tasks that could not be delivered are passed to the
+	// specified handler.
+	WatchDispatchQueue(handler common.DispatchFailureHandlerFunc)
 
 	// Send a message to the specified queue.
 	SendMessage(serviceId string, queueTopic string, msg *string) error
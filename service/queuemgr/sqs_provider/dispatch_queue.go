@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/common"
 	"github.com/hpinc/krypton-scheduler/service/db"
 	"github.com/hpinc/krypton-scheduler/service/mqtt"
 	"go.uber.org/zap"
@@ -12,7 +13,8 @@ import (
 
 // Watch the scheduler dispatch queue for new requests at the configured watch
 // interval.
-func (p *SqsQueueProvider) WatchDispatchQueue() {
+func (p *SqsQueueProvider) WatchDispatchQueue(
+	handler common.DispatchFailureHandlerFunc) {
 	schedLogger.Info("Dispatch Queue Watcher: Watching the scheduler dispatch queue for requests!",
 		zap.String("Queue name:", p.queueConfig.DispatchQueueName),
 		zap.Int32("Watch delay:", p.queueConfig.WatchDelay),
@@ -27,14 +29,15 @@ func (p *SqsQueueProvider) WatchDispatchQueue() {
 		}
 
 		// Look for requests on the scheduler dispatch queue.
-		p.processSchedulerDispatchRequest()
+		p.processSchedulerDispatchRequest(handler)
 	}
 }
 
 // Retrieve a single message from the scheduler dispatch queue and dispatch it
 // for processing. This function will process messages from the dispatch queue
 // one at a time until there are no more messages on the queue.
-func (p *SqsQueueProvider) processSchedulerDispatchRequest() {
+func (p *SqsQueueProvider) processSchedulerDispatchRequest(
+	handler common.DispatchFailureHandlerFunc) {
 
 	// Receive a single message from the scheduler dispatch queue.
 	taskInfo, payload, receiptHandle, err := p.receiveDispatchQueueMessage()
@@ -49,6 +52,26 @@ func (p *SqsQueueProvider) processSchedulerDispatchRequest() {
 		return
 	}
 
+	// Drop messages for tasks which were removed, cancelled or paused after
+	// the message was queued for dispatch.
+	dispatch, err := db.ShouldDispatchTask(taskInfo)
+	if err != nil {
+		schedLogger.Error("Failed to retrieve the task for the dispatched message!",
+			zap.String("Task ID: ", taskInfo.TaskId),
+			zap.String("Device ID: ", taskInfo.DeviceId),
+			zap.Error(err),
+		)
+		return
+	}
+	if !dispatch {
+		schedLogger.Info("Dropping a message for a task that is no longer active!",
+			zap.String("Task ID: ", taskInfo.TaskId),
+			zap.String("Device ID: ", taskInfo.DeviceId),
+		)
+		p.deleteDispatchQueueMessage(taskInfo, receiptHandle)
+		return
+	}
+
 	// Dispatch the received message to the MQTT broker for delivery to the
 	// target device.
 	err = mqtt.SendTaskToBroker(
@@ -60,6 +83,21 @@ func (p *SqsQueueProvider) processSchedulerDispatchRequest() {
 			zap.String("Device ID: ", taskInfo.DeviceId),
 			zap.Error(err),
 		)
+
+		// Hand the task over to the scheduler, which retries it as per its
+		// retry policy. If the scheduler fails to process the task, leave the
+		// message on the queue so that it is delivered again.
+		err = handler(taskInfo)
+		if err != nil {
+			schedLogger.Error("Failed to process the undelivered task!",
+				zap.String("Task ID: ", taskInfo.TaskId),
+				zap.String("Device ID: ", taskInfo.DeviceId),
+				zap.Error(err),
+			)
+			return
+		}
+
+		p.deleteDispatchQueueMessage(taskInfo, receiptHandle)
 		return
 	}
 
@@ -75,7 +113,14 @@ func (p *SqsQueueProvider) processSchedulerDispatchRequest() {
 	}
 
 	// Delete the processed message from the scheduler dispatch queue.
-	err = p.deleteMessage(p.schedulerDispatchQueueUrl, receiptHandle)
+	p.deleteDispatchQueueMessage(taskInfo, receiptHandle)
+}
+
+// Delete the processed message for the specified task from the scheduler
+// dispatch queue.
+func (p *SqsQueueProvider) deleteDispatchQueueMessage(
+	taskInfo *pb.ServiceMessage, receiptHandle string) {
+	err := p.deleteMessage(p.schedulerDispatchQueueUrl, receiptHandle)
 	if err != nil {
 		schedLogger.Error("Failed to remove message from scheduler dispatch queue!",
 			zap.String("Task ID: ", taskInfo.TaskId),
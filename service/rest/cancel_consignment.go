@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
+)
+
+// CancelConsignment REST request handler - cancels every task in the specified
+// consignment that has not yet finished and returns a summary of the number of
+// tasks that were cancelled and that had already finished. If the consignment
+// has too many tasks to cancel within the request, the remaining tasks are
+// cancelled in the background and 202 Accepted is returned with a summary of
+// the tasks processed so far.
+// Parameters:
+//   - consignment_id - The consignment being cancelled is specified in the URL
+//     eg. api/v1/consignments/{consignment_id}
+//   - tenant_id - The unique ID of the tenant which owns the consignment.
+//   - notify_device - Optional. If true, devices are asked to stop executing
+//     the cancelled tasks.
+func CancelConsignmentHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the consignment ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	consignmentID := params[paramConsignmentID]
+	if consignmentID == "" {
+		schedLogger.Error("Received an invalid request with no consignment ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingConsignmentId)
+		metrics.MetricCancelConsignmentBadRequests.Inc()
+		return
+	}
+
+	// Extract the tenant ID from the query parameter. If not specified,
+	// reject the request as bad.
+	tenantID := r.URL.Query().Get(paramTenantID)
+	if tenantID == "" {
+		schedLogger.Error("Received an invalid request with no tenant ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTenantId)
+		metrics.MetricCancelConsignmentBadRequests.Inc()
+		return
+	}
+
+	// Check if devices need to be asked to cancel the tasks.
+	notifyDevice := false
+	if value := r.URL.Query().Get(paramNotifyDevice); value != "" {
+		var err error
+		notifyDevice, err = strconv.ParseBool(value)
+		if err != nil {
+			schedLogger.Error("Request contains an invalid notify_device parameter!",
+				zap.String("Request ID: ", requestID),
+			)
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidNotifyDevice)
+			metrics.MetricCancelConsignmentBadRequests.Inc()
+			return
+		}
+	}
+
+	summary, err := scheduler.CancelConsignmentHandlerFunc(tenantID,
+		consignmentID, notifyDevice)
+	if err != nil {
+		schedLogger.Error("Failed to cancel the specified consignment!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Consignment ID: ", consignmentID),
+			zap.Error(err),
+		)
+		if err == db.ErrInvalidRequest {
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricCancelConsignmentBadRequests.Inc()
+			return
+		} else if err == db.ErrNotFound {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricCancelConsignmentNotFoundErrors.Inc()
+			return
+		}
+
+		sendInternalServerErrorResponse(w)
+		metrics.MetricCancelConsignmentInternalErrors.Inc()
+		return
+	}
+
+	status := http.StatusOK
+	if summary.InProgress {
+		status = http.StatusAccepted
+	}
+	err = sendJsonResponse(w, status, summary)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricCancelConsignmentInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricCancelConsignmentResponses.Inc()
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"time"
@@ -96,6 +97,13 @@ func CreateTaskHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("Request ID: ", requestID),
 			zap.Error(err),
 		)
+		var scheduleErr *scheduler.ScheduleError
+		if errors.As(err, &scheduleErr) {
+			sendInvalidScheduleErrorResponse(w, requestID, err)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+		}
+
 		switch err {
 		case db.ErrInvalidRequest, scheduler.ErrInvalidSchedulingUnit,
 			scheduler.ErrInvalidRequest:
@@ -103,6 +111,41 @@ func CreateTaskHandler(w http.ResponseWriter, r *http.Request) {
 			metrics.MetricCreateTaskBadRequests.Inc()
 			return
 
+		case scheduler.ErrInvalidTimezone:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidTimezone)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
+		case scheduler.ErrInvalidMisfirePolicy:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidMisfirePolicy)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
+		case scheduler.ErrInvalidEndAt:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidEndAt)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
+		case scheduler.ErrInvalidMaxRuns:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidMaxRuns)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
+		case scheduler.ErrInvalidJitter:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidJitter)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
+		case scheduler.ErrInvalidRolloutPolicy:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidRollout)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
+		case scheduler.ErrRolloutExists:
+			sendBadRequestErrorResponse(w, requestID, reasonRolloutExists)
+			metrics.MetricCreateTaskBadRequests.Inc()
+			return
+
 		default:
 			sendInternalServerErrorResponse(w)
 			metrics.MetricCreateTaskInternalErrors.Inc()
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"go.uber.org/zap"
+)
+
+// GetConsignmentSummary REST request handler - returns the number of tasks in
+// the specified consignment with each status, along with the completion
+// percentage of the consignment.
+// Parameters:
+//   - consignment_id - The consignment is specified in the URL
+//     eg. api/v1/consignments/{consignment_id}/summary
+//   - tenant_id - The unique ID of the tenant which owns the consignment.
+func GetConsignmentSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the consignment ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	consignmentID := params[paramConsignmentID]
+	if consignmentID == "" {
+		schedLogger.Error("Received an invalid request with no consignment ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingConsignmentId)
+		metrics.MetricGetConsignmentSummaryBadRequests.Inc()
+		return
+	}
+
+	// Extract the tenant ID from the query parameter. If not specified,
+	// reject the request as bad.
+	tenantID := r.URL.Query().Get(paramTenantID)
+	if tenantID == "" {
+		schedLogger.Error("Received an invalid request with no tenant ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTenantId)
+		metrics.MetricGetConsignmentSummaryBadRequests.Inc()
+		return
+	}
+
+	summary, err := db.GetConsignmentSummary(tenantID, consignmentID)
+	if err != nil {
+		schedLogger.Error("Failed to get the consignment summary!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Consignment ID: ", consignmentID),
+			zap.Error(err),
+		)
+		if err == db.ErrInvalidRequest {
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricGetConsignmentSummaryBadRequests.Inc()
+			return
+		} else if err == db.ErrNotFound {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricGetConsignmentSummaryNotFoundErrors.Inc()
+			return
+		}
+
+		sendInternalServerErrorResponse(w)
+		metrics.MetricGetConsignmentSummaryInternalErrors.Inc()
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, summary)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricGetConsignmentSummaryInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricGetConsignmentSummaryResponses.Inc()
+}
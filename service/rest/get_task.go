@@ -7,9 +7,20 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/hpinc/krypton-scheduler/service/db"
 	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
 	"go.uber.org/zap"
 )
 
+// Task information returned for a task, along with the number of runs of the
+// task which remain within the bounds of its schedule.
+type taskResponse struct {
+	*db.Task
+
+	// The number of remaining runs of the task, including its next scheduled
+	// run. Omitted if the schedule of the task is not bounded.
+	RemainingRuns *int `json:"remaining_runs,omitempty"`
+}
+
 func GetTaskHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract the request ID.
 	requestID := r.Header.Get(headerRequestID)
@@ -71,7 +82,11 @@ func GetTaskHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return the task information to the caller.
-	err = sendJsonResponse(w, http.StatusOK, foundTask)
+	response := taskResponse{Task: foundTask}
+	if remaining, ok := scheduler.RemainingRuns(foundTask); ok {
+		response.RemainingRuns = &remaining
+	}
+	err = sendJsonResponse(w, http.StatusOK, response)
 	if err != nil {
 		schedLogger.Error("Failed to encode JSON response!",
 			zap.String("Request ID: ", requestID),
@@ -0,0 +1,128 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"go.uber.org/zap"
+)
+
+// ListDeviceTasksResponse - JSON encoded response to the ListDeviceTasks REST
+// request.
+type ListDeviceTasksResponse struct {
+	Count         int       `json:"count"`
+	Tasks         []db.Task `json:"tasks,omitempty"`
+	NextPageToken string    `json:"next_page_token,omitempty"`
+	ResponseTime  time.Time `json:"response_time"`
+}
+
+// ListDeviceTasks REST request handler - returns the tasks scheduled for the
+// specified device.
+// Parameters:
+//   - device_id - The device is specified in the URL
+//     eg. api/v1/devices/{device_id}/tasks
+//   - tenant_id - The unique ID of the tenant to which the device belongs.
+//   - status - Optional. Only return tasks with this status.
+//   - message_type - Optional. Only return tasks with this message type.
+//   - page_token - Optional token returned with the previous page of tasks.
+//   - page_size - Optional maximum number of tasks to return.
+func ListDeviceTasksHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the device ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	deviceID := params[paramDeviceID]
+	_, err := uuid.Parse(deviceID)
+	if err != nil {
+		schedLogger.Error("Received a request with an invalid device ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonInvalidDeviceId)
+		metrics.MetricListDeviceTasksBadRequests.Inc()
+		return
+	}
+
+	// Extract the tenant ID from the query parameter. If not specified,
+	// reject the request as bad.
+	tenantID := r.URL.Query().Get(paramTenantID)
+	if tenantID == "" {
+		schedLogger.Error("Received an invalid request with no tenant ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTenantId)
+		metrics.MetricListDeviceTasksBadRequests.Inc()
+		return
+	}
+
+	filter := &db.TaskFilter{
+		TenantID:    tenantID,
+		Status:      r.URL.Query().Get(paramStatus),
+		MessageType: r.URL.Query().Get(paramMessageType),
+	}
+	if filter.Status != "" && !db.IsValidTaskStatus(filter.Status) {
+		schedLogger.Error("Received a request with an invalid status!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonInvalidStatus)
+		metrics.MetricListDeviceTasksBadRequests.Inc()
+		return
+	}
+
+	pageState, pageSize, err := getPageParams(r)
+	if err != nil {
+		schedLogger.Error("Received a request with invalid pagination parameters!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, err.Error())
+		metrics.MetricListDeviceTasksBadRequests.Inc()
+		return
+	}
+
+	foundTasks, nextPage, err := (&db.Task{}).GetTasksForDeviceID(deviceID,
+		filter, pageState, pageSize)
+	if err != nil {
+		schedLogger.Error("Failed to query tasks for the device from the scheduler database!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricListDeviceTasksInternalErrors.Inc()
+		return
+	}
+
+	resp := ListDeviceTasksResponse{
+		Count:         len(foundTasks),
+		NextPageToken: encodePageToken(nextPage),
+		ResponseTime:  time.Now(),
+	}
+	for _, item := range foundTasks {
+		resp.Tasks = append(resp.Tasks, *item)
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, resp)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricListDeviceTasksInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricListDeviceTasksResponses.Inc()
+}
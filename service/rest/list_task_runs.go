@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"go.uber.org/zap"
+)
+
+// ListTaskRunsResponse - JSON encoded response to the ListTaskRuns REST request.
+type ListTaskRunsResponse struct {
+	Count         int          `json:"count"`
+	Runs          []db.TaskRun `json:"runs,omitempty"`
+	NextPageToken string       `json:"next_page_token,omitempty"`
+	ResponseTime  time.Time    `json:"response_time"`
+}
+
+// ListTaskRuns REST request handler - returns the run history of the specified
+// task, starting with the most recent run.
+// Parameters:
+//   - task_id - The unique ID of the task.
+//   - device_id - The unique ID of the device to which the task belongs.
+//   - page_token - Optional token returned with the previous page of runs.
+//   - page_size - Optional maximum number of runs to return.
+func ListTaskRunsHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the task ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	taskID := params[paramTaskID]
+	if taskID == "" {
+		schedLogger.Error("Received an invalid request with no task ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTaskId)
+		metrics.MetricListTaskRunsBadRequests.Inc()
+		return
+	}
+
+	// Extract the device ID from the query parameter. If not specified,
+	// reject the request as bad.
+	deviceID := r.URL.Query().Get(paramDeviceID)
+	_, err := uuid.Parse(deviceID)
+	if err != nil {
+		schedLogger.Error("Received a request with an invalid device ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingDeviceId)
+		metrics.MetricListTaskRunsBadRequests.Inc()
+		return
+	}
+
+	pageState, pageSize, err := getPageParams(r)
+	if err != nil {
+		schedLogger.Error("Received a request with invalid pagination parameters!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, err.Error())
+		metrics.MetricListTaskRunsBadRequests.Inc()
+		return
+	}
+
+	// Only return the run history of the task if it belongs to the device.
+	_, err = db.GetTaskByID(taskID, deviceID)
+	if err != nil {
+		schedLogger.Error("Failed to get task information!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Task ID: ", taskID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		if err == db.ErrInvalidRequest {
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricListTaskRunsBadRequests.Inc()
+			return
+		} else if err == db.ErrNotFound {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricListTaskRunsNotFoundErrors.Inc()
+			return
+		}
+
+		sendInternalServerErrorResponse(w)
+		metrics.MetricListTaskRunsInternalErrors.Inc()
+		return
+	}
+
+	foundRuns, nextPage, err := db.GetTaskRuns(taskID, pageState, pageSize)
+	if err != nil {
+		schedLogger.Error("Failed to query runs of the task from the scheduler database!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Task ID: ", taskID),
+			zap.Error(err),
+		)
+		if err == db.ErrInvalidRequest {
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricListTaskRunsBadRequests.Inc()
+			return
+		}
+
+		sendInternalServerErrorResponse(w)
+		metrics.MetricListTaskRunsInternalErrors.Inc()
+		return
+	}
+
+	resp := ListTaskRunsResponse{
+		Count:         len(foundRuns),
+		NextPageToken: encodePageToken(nextPage),
+		ResponseTime:  time.Now(),
+	}
+	for _, item := range foundRuns {
+		resp.Runs = append(resp.Runs, *item)
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, resp)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricListTaskRunsInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricListTaskRunsResponses.Inc()
+}
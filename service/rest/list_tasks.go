@@ -1,7 +1,6 @@
 package rest
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -12,12 +11,27 @@ import (
 
 // ListTasksResponse - JSON encoded response to the ListTasks REST request.
 type ListTasksResponse struct {
-	Count        int              `json:"count"`
-	Tasks        []db.Consignment `json:"tasks,omitempty"`
-	NextPage     string           `json:"next_page,omitempty"`
-	ResponseTime time.Time        `json:"response_time"`
+	Count         int              `json:"count"`
+	Tasks         []db.Consignment `json:"tasks,omitempty"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
+
+	// Deprecated: the same token as NextPageToken, kept for existing clients.
+	NextPage string `json:"next_page,omitempty"`
+
+	ResponseTime time.Time `json:"response_time"`
 }
 
+// ListTasks REST request handler - returns the tasks in the specified
+// consignment.
+// Parameters:
+//   - consignment_id - The consignment to which the tasks belong.
+//   - tenant_id - The unique ID of the tenant which owns the consignment.
+//   - status - Optional. Only return tasks with this status.
+//   - device_id - Optional. Only return tasks for this device.
+//   - created_after, created_before - Optional. Only return tasks created
+//     within this time range, specified in RFC 3339 format.
+//   - page_token - Optional token returned with the previous page of tasks.
+//   - page_size - Optional maximum number of tasks to return.
 func ListTasksHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		foundConsignments []*db.Consignment
@@ -58,8 +72,30 @@ func ListTasksHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := getTaskFilter(r)
+	if err != nil {
+		schedLogger.Error("Received a request with invalid filter parameters!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, err.Error())
+		metrics.MetricListTasksBadRequests.Inc()
+		return
+	}
+
+	pageState, pageSize, err := getPageParams(r)
+	if err != nil {
+		schedLogger.Error("Received a request with invalid pagination parameters!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, err.Error())
+		metrics.MetricListTasksBadRequests.Inc()
+		return
+	}
+
 	foundConsignments, nextPage, err = db.GetTasksForConsignment(tenantID,
-		consignmentID, nextPage, 0)
+		consignmentID, filter, pageState, pageSize)
 	if err != nil {
 		schedLogger.Error("Failed to query tasks for specified consignment from the scheduler database!",
 			zap.Error(err),
@@ -69,24 +105,27 @@ func ListTasksHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set(headerContentType, contentTypeJson)
 	resp := ListTasksResponse{
-		Count:        len(foundConsignments),
-		Tasks:        nil,
-		NextPage:     string(nextPage),
-		ResponseTime: time.Now(),
+		Count:         len(foundConsignments),
+		Tasks:         nil,
+		NextPageToken: encodePageToken(nextPage),
+		NextPage:      encodePageToken(nextPage),
+		ResponseTime:  time.Now(),
 	}
 	for _, item := range foundConsignments {
 		resp.Tasks = append(resp.Tasks, *item)
 	}
 
-	err = json.NewEncoder(w).Encode(resp)
+	err = sendJsonResponse(w, http.StatusOK, resp)
 	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
 		sendInternalServerErrorResponse(w)
 		metrics.MetricListTasksInternalErrors.Inc()
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	metrics.MetricListTasksReponses.Inc()
 }
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	b64 "encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// The maximum number of items that may be requested in a single page.
+	maxPageSize = 500
+)
+
+var (
+	errInvalidPageToken = errors.New(reasonInvalidPageToken)
+	errInvalidPageSize  = errors.New(reasonInvalidPageSize)
+)
+
+// Extract the page token and page size from the request query string. The page
+// token is the opaque database page state returned in the previous page of
+// results, base64url encoded. A page size of zero requests the default page
+// size.
+func getPageParams(r *http.Request) ([]byte, int, error) {
+	var (
+		pageState []byte
+		pageSize  int
+		err       error
+	)
+
+	pageToken := r.URL.Query().Get(paramPageToken)
+	if pageToken != "" {
+		pageState, err = b64.RawURLEncoding.DecodeString(pageToken)
+		if err != nil {
+			return nil, 0, errInvalidPageToken
+		}
+	}
+
+	size := r.URL.Query().Get(paramPageSize)
+	if size != "" {
+		pageSize, err = strconv.Atoi(size)
+		if err != nil || pageSize <= 0 || pageSize > maxPageSize {
+			return nil, 0, errInvalidPageSize
+		}
+	}
+
+	return pageState, pageSize, nil
+}
+
+// Encode the database page state as a page token which can be used to request
+// the next page of results.
+func encodePageToken(pageState []byte) string {
+	if len(pageState) == 0 {
+		return ""
+	}
+	return b64.RawURLEncoding.EncodeToString(pageState)
+}
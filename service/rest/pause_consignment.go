@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
+)
+
+// PauseConsignment REST request handler - pauses every task in the specified
+// consignment that has not yet finished and returns a summary of the number of
+// tasks that were paused and that were skipped.
+// Parameters:
+//   - consignment_id - The consignment being paused is specified in the URL
+//     eg. api/v1/consignments/{consignment_id}:pause
+//   - tenant_id - The unique ID of the tenant which owns the consignment.
+func PauseConsignmentHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the consignment ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	consignmentID := params[paramConsignmentID]
+	if consignmentID == "" {
+		schedLogger.Error("Received an invalid request with no consignment ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingConsignmentId)
+		metrics.MetricPauseConsignmentBadRequests.Inc()
+		return
+	}
+
+	// Extract the tenant ID from the query parameter. If not specified,
+	// reject the request as bad.
+	tenantID := r.URL.Query().Get(paramTenantID)
+	if tenantID == "" {
+		schedLogger.Error("Received an invalid request with no tenant ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTenantId)
+		metrics.MetricPauseConsignmentBadRequests.Inc()
+		return
+	}
+
+	summary, err := scheduler.PauseConsignmentHandlerFunc(tenantID,
+		consignmentID)
+	if err != nil {
+		schedLogger.Error("Failed to pause the specified consignment!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Consignment ID: ", consignmentID),
+			zap.Error(err),
+		)
+		if err == db.ErrInvalidRequest {
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricPauseConsignmentBadRequests.Inc()
+			return
+		}
+
+		sendInternalServerErrorResponse(w)
+		metrics.MetricPauseConsignmentInternalErrors.Inc()
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, summary)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricPauseConsignmentInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricPauseConsignmentResponses.Inc()
+}
+
+// ResumeConsignment REST request handler - resumes every paused task in the
+// specified consignment and returns a summary of the number of tasks that were
+// resumed and that were skipped.
+// Parameters:
+//   - consignment_id - The consignment being resumed is specified in the URL
+//     eg. api/v1/consignments/{consignment_id}:resume
+//   - tenant_id - The unique ID of the tenant which owns the consignment.
+//   - misfire_policy - Optional. Either fire_once to run tasks which missed
+//     runs while paused once right away, skip to run them at their next
+//     scheduled time, or "fire_if_within <duration>" to run them right away
+//     only if the missed run was scheduled within that duration. If not
+//     specified, the misfire policy of each task is applied.
+func ResumeConsignmentHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the consignment ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	consignmentID := params[paramConsignmentID]
+	if consignmentID == "" {
+		schedLogger.Error("Received an invalid request with no consignment ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingConsignmentId)
+		metrics.MetricResumeConsignmentBadRequests.Inc()
+		return
+	}
+
+	// Extract the tenant ID from the query parameter. If not specified,
+	// reject the request as bad.
+	tenantID := r.URL.Query().Get(paramTenantID)
+	if tenantID == "" {
+		schedLogger.Error("Received an invalid request with no tenant ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTenantId)
+		metrics.MetricResumeConsignmentBadRequests.Inc()
+		return
+	}
+
+	policy, err := scheduler.ParseResumeMisfirePolicy(
+		r.URL.Query().Get(paramMisfirePolicy))
+	if err != nil {
+		schedLogger.Error("Request contains an invalid misfire policy!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonInvalidMisfirePolicy)
+		metrics.MetricResumeConsignmentBadRequests.Inc()
+		return
+	}
+
+	summary, err := scheduler.ResumeConsignmentHandlerFunc(tenantID,
+		consignmentID, policy)
+	if err != nil {
+		schedLogger.Error("Failed to resume the specified consignment!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Consignment ID: ", consignmentID),
+			zap.Error(err),
+		)
+		if err == db.ErrInvalidRequest {
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricResumeConsignmentBadRequests.Inc()
+			return
+		}
+
+		sendInternalServerErrorResponse(w)
+		metrics.MetricResumeConsignmentInternalErrors.Inc()
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, summary)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricResumeConsignmentInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricResumeConsignmentResponses.Inc()
+}
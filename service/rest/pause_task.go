@@ -0,0 +1,209 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
+)
+
+// PauseTask REST request handler - pauses the specified task so that it is not
+// dispatched to the device until it is resumed, and returns the paused task.
+// Parameters:
+//   - task_id - The unique ID of the task being paused is specified in the URL
+//     eg. api/v1/tasks/{task_id}:pause
+//   - device_id - The unique device ID of the device to which the task needs to
+//     be dispatched.
+func PauseTaskHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the task ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	taskID := params[paramTaskID]
+	if taskID == "" {
+		schedLogger.Error("Received an invalid request with no task ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTaskId)
+		metrics.MetricPauseTaskBadRequests.Inc()
+		return
+	}
+
+	// Extract the device ID from the query parameter. If not specified,
+	// reject the request as bad.
+	deviceID := r.URL.Query().Get(paramDeviceID)
+	_, err := uuid.Parse(deviceID)
+	if err != nil {
+		schedLogger.Error("Request contains an invalid device ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingDeviceId)
+		metrics.MetricPauseTaskBadRequests.Inc()
+		return
+	}
+
+	pausedTask, err := scheduler.PauseTaskHandlerFunc(taskID, deviceID)
+	if err != nil {
+		schedLogger.Error("Failed to pause the specified task!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Task ID: ", taskID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		switch err {
+		case db.ErrInvalidRequest:
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricPauseTaskBadRequests.Inc()
+
+		case db.ErrNotFound:
+			sendNotFoundErrorResponse(w)
+			metrics.MetricPauseTaskNotFoundErrors.Inc()
+
+		case scheduler.ErrTaskFinished:
+			sendConflictErrorResponse(w, requestID, reasonTaskFinished)
+			metrics.MetricPauseTaskBadRequests.Inc()
+
+		case db.ErrTaskStatusChanged:
+			sendConflictErrorResponse(w, requestID, reasonTaskStatusChanged)
+			metrics.MetricPauseTaskBadRequests.Inc()
+
+		default:
+			sendInternalServerErrorResponse(w)
+			metrics.MetricPauseTaskInternalErrors.Inc()
+		}
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, pausedTask)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricPauseTaskInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricPauseTaskResponses.Inc()
+}
+
+// ResumeTask REST request handler - resumes the specified paused task and
+// returns the resumed task. If runs of the task were missed while it was
+// paused, its next run is scheduled as per the requested misfire policy.
+// Parameters:
+//   - task_id - The unique ID of the task being resumed is specified in the URL
+//     eg. api/v1/tasks/{task_id}:resume
+//   - device_id - The unique device ID of the device to which the task needs to
+//     be dispatched.
+//   - misfire_policy - Optional. Either fire_once to run the task once right
+//     away, skip to run the task at its next scheduled time, or
+//     "fire_if_within <duration>" to run the task right away only if the
+//     missed run was scheduled within that duration. If not specified, the
+//     misfire policy of the task is applied.
+func ResumeTaskHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the task ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	taskID := params[paramTaskID]
+	if taskID == "" {
+		schedLogger.Error("Received an invalid request with no task ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTaskId)
+		metrics.MetricResumeTaskBadRequests.Inc()
+		return
+	}
+
+	// Extract the device ID from the query parameter. If not specified,
+	// reject the request as bad.
+	deviceID := r.URL.Query().Get(paramDeviceID)
+	_, err := uuid.Parse(deviceID)
+	if err != nil {
+		schedLogger.Error("Request contains an invalid device ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingDeviceId)
+		metrics.MetricResumeTaskBadRequests.Inc()
+		return
+	}
+
+	policy, err := scheduler.ParseResumeMisfirePolicy(
+		r.URL.Query().Get(paramMisfirePolicy))
+	if err != nil {
+		schedLogger.Error("Request contains an invalid misfire policy!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonInvalidMisfirePolicy)
+		metrics.MetricResumeTaskBadRequests.Inc()
+		return
+	}
+
+	resumedTask, err := scheduler.ResumeTaskHandlerFunc(taskID, deviceID,
+		policy)
+	if err != nil {
+		schedLogger.Error("Failed to resume the specified task!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Task ID: ", taskID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		switch err {
+		case db.ErrInvalidRequest:
+			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
+			metrics.MetricResumeTaskBadRequests.Inc()
+
+		case db.ErrNotFound:
+			sendNotFoundErrorResponse(w)
+			metrics.MetricResumeTaskNotFoundErrors.Inc()
+
+		case db.ErrTaskNotPaused:
+			sendConflictErrorResponse(w, requestID, reasonTaskNotPaused)
+			metrics.MetricResumeTaskBadRequests.Inc()
+
+		case db.ErrTaskStatusChanged:
+			sendConflictErrorResponse(w, requestID, reasonTaskStatusChanged)
+			metrics.MetricResumeTaskBadRequests.Inc()
+
+		default:
+			sendInternalServerErrorResponse(w)
+			metrics.MetricResumeTaskInternalErrors.Inc()
+		}
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, resumedTask)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricResumeTaskInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricResumeTaskResponses.Inc()
+}
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
+)
+
+// PreviewSchedule REST request handler - parses the specified schedule and
+// returns the times of the upcoming runs of a task with that schedule, without
+// scheduling a task. If the schedule cannot be parsed, the error response
+// identifies the offending token of the schedule.
+// Parameters:
+//   - The protobuf encoded PreviewScheduleRequest is specified in the body of
+//     the request.
+func PreviewScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the contents of the POST were provided using protobuf content
+	// type.
+	if r.Header.Get(headerContentType) != contentTypeProtobuf {
+		sendUnsupportedMediaTypeResponse(w)
+		metrics.MetricPreviewScheduleBadRequests.Inc()
+		return
+	}
+
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Retrieve the request parameters from the body.
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		schedLogger.Error("Failed to retrieve the request body!",
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonRequestParsingFailed)
+		metrics.MetricPreviewScheduleBadRequests.Inc()
+		return
+	}
+
+	// Unmarshal the request.
+	var request pb.PreviewScheduleRequest
+	err = proto.Unmarshal(reqBytes, &request)
+	if err != nil {
+		schedLogger.Error("Failed to unmarshal request received at scheduler REST endpoint!",
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonProtobufUnmarshalFailed)
+		metrics.MetricPreviewScheduleBadRequests.Inc()
+		return
+	}
+
+	if request.Schedule == "" {
+		schedLogger.Error("Received an invalid request with no schedule!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingSchedule)
+		metrics.MetricPreviewScheduleBadRequests.Inc()
+		return
+	}
+
+	preview, err := scheduler.PreviewScheduleHandlerFunc(request.Schedule,
+		request.Timezone, int(request.Count))
+	if err != nil {
+		schedLogger.Error("Failed to preview the specified schedule!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Schedule: ", request.Schedule),
+			zap.String("Timezone: ", request.Timezone),
+			zap.Error(err),
+		)
+		switch err {
+		case scheduler.ErrInvalidTimezone:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidTimezone)
+
+		case scheduler.ErrInvalidPreviewRunCount:
+			sendBadRequestErrorResponse(w, requestID,
+				reasonInvalidPreviewRunCount)
+
+		default:
+			sendInvalidScheduleErrorResponse(w, requestID, err)
+		}
+		metrics.MetricPreviewScheduleBadRequests.Inc()
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, preview)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricPreviewScheduleInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricPreviewScheduleResponses.Inc()
+}
+
+// Send a bad request response describing why the schedule could not be parsed.
+func sendInvalidScheduleErrorResponse(w http.ResponseWriter, requestID string,
+	err error) {
+	response := InvalidScheduleError{
+		HttpCode: http.StatusBadRequest,
+		Reason:   err.Error(),
+	}
+
+	var scheduleErr *scheduler.ScheduleError
+	if errors.As(err, &scheduleErr) {
+		response.Reason = scheduleErr.Err.Error()
+		response.Token = scheduleErr.Token
+		response.Position = &scheduleErr.Position
+		response.SupportedForms = scheduleErr.SupportedForms
+	}
+
+	err = sendJsonResponse(w, http.StatusBadRequest, response)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+	}
+}
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/hpinc/krypton-scheduler/service/db"
 	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
 )
 
 // RemoveTask REST request handler - removes the specified task request from the
@@ -18,6 +20,8 @@ import (
 //     eg. api/v1/tasks/{task_id}
 //   - device_id - The unique device ID of the device to which the task needs to
 //     be dispatched.
+//   - notify_device - Optional. If true, the task is cancelled instead of being
+//     removed and the device is asked to stop executing the task.
 func RemoveTaskHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract the request ID.
 	requestID := r.Header.Get(headerRequestID)
@@ -54,9 +58,28 @@ func RemoveTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Remove the task from the scheduler database.
-	removeTask := db.Task{}
-	err = removeTask.RemoveTask(taskID, deviceID)
+	// Check if the device needs to be asked to cancel the task.
+	notifyDevice := false
+	if value := r.URL.Query().Get(paramNotifyDevice); value != "" {
+		notifyDevice, err = strconv.ParseBool(value)
+		if err != nil {
+			schedLogger.Error("Request contains an invalid notify_device parameter!",
+				zap.String("Request ID: ", requestID),
+			)
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidNotifyDevice)
+			metrics.MetricRemoveTaskBadRequests.Inc()
+			return
+		}
+	}
+
+	// Cancel the task and notify the device, or remove the task from the
+	// scheduler database.
+	if notifyDevice {
+		err = scheduler.CancelTaskHandlerFunc(taskID, deviceID)
+	} else {
+		removeTask := db.Task{}
+		err = removeTask.RemoveTask(taskID, deviceID)
+	}
 	if err != nil {
 		schedLogger.Error("Failed to remove the specified task!",
 			zap.String("Request ID: ", requestID),
@@ -68,6 +91,14 @@ func RemoveTaskHandler(w http.ResponseWriter, r *http.Request) {
 			sendBadRequestErrorResponse(w, requestID, reasonFailedRequestDbError)
 			metrics.MetricRemoveTaskBadRequests.Inc()
 			return
+		} else if err == db.ErrNotFound {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricRemoveTaskNotFoundErrors.Inc()
+			return
+		} else if err == db.ErrTaskStatusChanged {
+			sendConflictErrorResponse(w, requestID, reasonTaskStatusChanged)
+			metrics.MetricRemoveTaskBadRequests.Inc()
+			return
 		}
 
 		sendInternalServerErrorResponse(w)
@@ -17,4 +17,12 @@ const (
 	paramConsignmentID = "consignment_id"
 	paramTaskDetails   = "task_details"
 	paramTaskSchedule  = "task_schedule"
+	paramPageToken     = "page_token"
+	paramPageSize      = "page_size"
+	paramNotifyDevice  = "notify_device"
+	paramStatus        = "status"
+	paramCreatedAfter  = "created_after"
+	paramCreatedBefore = "created_before"
+	paramMessageType   = "message_type"
+	paramMisfirePolicy = "misfire_policy"
 )
@@ -12,6 +12,18 @@ type FailedRequestError struct {
 	Reason   string `json:"reason"`
 }
 
+// InvalidScheduleError - JSON encoded response to a request specifying a
+// schedule which could not be parsed. If the error was caused by a specific
+// token of the schedule, the token and its offset in the schedule are included,
+// along with the forms of schedule which are supported.
+type InvalidScheduleError struct {
+	HttpCode       uint     `json:"code"`
+	Reason         string   `json:"reason"`
+	Token          string   `json:"token,omitempty"`
+	Position       *int     `json:"position,omitempty"`
+	SupportedForms []string `json:"supported_forms,omitempty"`
+}
+
 const (
 	// #nosec spurious G101 (CWE-798): Potential hardcoded credentials
 	reasonInvalidAppToken         = "invalid app access token specified"
@@ -24,6 +36,24 @@ const (
 	reasonMissingDeviceId         = "device_id parameter was not specified"
 	reasonMissingTenantId         = "tenant_id parameter was not specified"
 	reasonMissingConsignmentId    = "consignment_id parameter was not specified"
+	reasonInvalidPageToken        = "page_token parameter is invalid"
+	reasonInvalidPageSize         = "page_size parameter is invalid"
+	reasonInvalidNotifyDevice     = "notify_device parameter is invalid"
+	reasonInvalidStatus           = "status parameter is invalid"
+	reasonInvalidDeviceId         = "device_id parameter is invalid"
+	reasonInvalidCreateTime       = "created_after or created_before parameter is invalid"
+	reasonTaskFinished            = "the task has already finished"
+	reasonTaskNotPaused           = "the task is not paused"
+	reasonTaskStatusChanged       = "the status of the task changed while processing the request"
+	reasonInvalidMisfirePolicy    = "misfire_policy parameter is invalid"
+	reasonMissingSchedule         = "schedule was not specified"
+	reasonInvalidTimezone         = "timezone is invalid"
+	reasonInvalidPreviewRunCount  = "count must be between 1 and 100"
+	reasonInvalidEndAt            = "end_at must be an RFC 3339 time in the future"
+	reasonInvalidMaxRuns          = "max_runs must not be negative"
+	reasonInvalidJitter           = "jitter must be a positive duration"
+	reasonInvalidRollout          = "rollout is invalid"
+	reasonRolloutExists           = "a rollout already exists for the consignment"
 )
 
 func sendInternalServerErrorResponse(w http.ResponseWriter) {
@@ -45,6 +75,20 @@ func sendBadRequestErrorResponse(w http.ResponseWriter, requestID string,
 	}
 }
 
+func sendConflictErrorResponse(w http.ResponseWriter, requestID string,
+	reason string) {
+	err := sendJsonResponse(w, http.StatusConflict, FailedRequestError{
+		HttpCode: http.StatusConflict,
+		Reason:   reason,
+	})
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+	}
+}
+
 func sendUnsupportedMediaTypeResponse(w http.ResponseWriter) {
 	http.Error(w, http.StatusText(http.StatusUnsupportedMediaType),
 		http.StatusUnsupportedMediaType)
@@ -60,4 +60,70 @@ var registeredRoutes = routes{
 		Path:        "/api/v1/tasks/{task_id}",
 		HandlerFunc: RemoveTaskHandler,
 	},
+	Route{
+		Name:        "UpdateTask",
+		Method:      http.MethodPatch,
+		Path:        "/api/v1/tasks/{task_id}",
+		HandlerFunc: UpdateTaskHandler,
+	},
+	Route{
+		Name:        "ListTaskRuns",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/tasks/{task_id}/runs",
+		HandlerFunc: ListTaskRunsHandler,
+	},
+	Route{
+		Name:        "PauseTask",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/tasks/{task_id}:pause",
+		HandlerFunc: PauseTaskHandler,
+	},
+	Route{
+		Name:        "ResumeTask",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/tasks/{task_id}:resume",
+		HandlerFunc: ResumeTaskHandler,
+	},
+
+	// Schedule methods.
+	Route{
+		Name:        "PreviewSchedule",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/schedules:preview",
+		HandlerFunc: PreviewScheduleHandler,
+	},
+
+	// Consignment methods.
+	Route{
+		Name:        "CancelConsignment",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/consignments/{consignment_id}",
+		HandlerFunc: CancelConsignmentHandler,
+	},
+	Route{
+		Name:        "GetConsignmentSummary",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/consignments/{consignment_id}/summary",
+		HandlerFunc: GetConsignmentSummaryHandler,
+	},
+	Route{
+		Name:        "PauseConsignment",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/consignments/{consignment_id}:pause",
+		HandlerFunc: PauseConsignmentHandler,
+	},
+	Route{
+		Name:        "ResumeConsignment",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/consignments/{consignment_id}:resume",
+		HandlerFunc: ResumeConsignmentHandler,
+	},
+
+	// Device methods.
+	Route{
+		Name:        "ListDeviceTasks",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/devices/{device_id}/tasks",
+		HandlerFunc: ListDeviceTasksHandler,
+	},
 }
@@ -46,7 +46,8 @@ func init() {
 		os.Exit(2)
 	}
 
-	err = queuemgr.Init(logger, cfgMgr, scheduler.ScheduleRequestHandlerFunc)
+	err = queuemgr.Init(logger, cfgMgr, scheduler.ScheduleRequestHandlerFunc,
+		scheduler.DispatchFailureHandlerFunc)
 	if err != nil {
 		fmt.Printf("Failed to initialize the queue manager with error %v\n", err)
 		os.Exit(2)
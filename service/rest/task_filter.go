@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+var (
+	errInvalidStatus     = errors.New(reasonInvalidStatus)
+	errInvalidDeviceID   = errors.New(reasonInvalidDeviceId)
+	errInvalidCreateTime = errors.New(reasonInvalidCreateTime)
+)
+
+// Extract the criteria used to filter tasks from the request query string.
+// Creation times are specified in RFC 3339 format.
+func getTaskFilter(r *http.Request) (*db.TaskFilter, error) {
+	var err error
+
+	query := r.URL.Query()
+	filter := &db.TaskFilter{
+		Status:   query.Get(paramStatus),
+		DeviceID: query.Get(paramDeviceID),
+	}
+
+	if filter.Status != "" && !db.IsValidTaskStatus(filter.Status) {
+		return nil, errInvalidStatus
+	}
+
+	if filter.DeviceID != "" {
+		_, err = uuid.Parse(filter.DeviceID)
+		if err != nil {
+			return nil, errInvalidDeviceID
+		}
+	}
+
+	if value := query.Get(paramCreatedAfter); value != "" {
+		filter.CreatedAfter, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errInvalidCreateTime
+		}
+	}
+
+	if value := query.Get(paramCreatedBefore); value != "" {
+		filter.CreatedBefore, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errInvalidCreateTime
+		}
+	}
+
+	if !filter.CreatedAfter.IsZero() && !filter.CreatedBefore.IsZero() &&
+		filter.CreatedBefore.Before(filter.CreatedAfter) {
+		return nil, errInvalidCreateTime
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"github.com/hpinc/krypton-scheduler/service/scheduler"
+)
+
+// UpdateTask REST request handler - updates the schedule, message type and/or
+// payload of the specified task in place and returns the updated task.
+// Parameters:
+//   - task_id - The unique ID of the task being updated is specified in the URL
+//     eg. api/v1/tasks/{task_id}
+//   - device_id - The unique device ID of the device to which the task needs to
+//     be dispatched.
+//   - The protobuf encoded UpdateScheduledTaskRequest is specified in the body
+//     of the request.
+func UpdateTaskHandler(w http.ResponseWriter, r *http.Request) {
+	// Check if the contents of the PATCH were provided using protobuf content
+	// type.
+	if r.Header.Get(headerContentType) != contentTypeProtobuf {
+		sendUnsupportedMediaTypeResponse(w)
+		metrics.MetricUpdateTaskBadRequests.Inc()
+		return
+	}
+
+	// Extract the request ID.
+	requestID := r.Header.Get(headerRequestID)
+
+	// Check if the request provided a valid app access token.
+	if isValidAppAccessToken(r) != nil {
+		sendUnauthorizedErrorResponse(w, requestID, reasonInvalidAppToken)
+		return
+	}
+
+	// Extract the task ID from the request path. If not specified,
+	// reject the request as bad.
+	params := mux.Vars(r)
+	taskID := params[paramTaskID]
+	if taskID == "" {
+		schedLogger.Error("Received an invalid request with no task ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingTaskId)
+		metrics.MetricUpdateTaskBadRequests.Inc()
+		return
+	}
+
+	// Extract the device ID from the query parameter. If not specified,
+	// reject the request as bad.
+	deviceID := r.URL.Query().Get(paramDeviceID)
+	_, err := uuid.Parse(deviceID)
+	if err != nil {
+		schedLogger.Error("Request contains an invalid device ID!",
+			zap.String("Request ID: ", requestID),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonMissingDeviceId)
+		metrics.MetricUpdateTaskBadRequests.Inc()
+		return
+	}
+
+	// Retrieve the request parameters from the body.
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		schedLogger.Error("Failed to retrieve the request body!",
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonRequestParsingFailed)
+		metrics.MetricUpdateTaskBadRequests.Inc()
+		return
+	}
+
+	// Unmarshal the request.
+	var request pb.UpdateScheduledTaskRequest
+	err = proto.Unmarshal(reqBytes, &request)
+	if err != nil {
+		schedLogger.Error("Failed to unmarshal request received at scheduler REST endpoint!",
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w, requestID, reasonProtobufUnmarshalFailed)
+		metrics.MetricUpdateTaskBadRequests.Inc()
+		return
+	}
+
+	updatedTask, err := scheduler.UpdateTaskHandlerFunc(taskID, deviceID,
+		&request)
+	if err != nil {
+		schedLogger.Error("Failed to update the specified task!",
+			zap.String("Request ID: ", requestID),
+			zap.String("Task ID: ", taskID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		var scheduleErr *scheduler.ScheduleError
+		if errors.As(err, &scheduleErr) {
+			sendInvalidScheduleErrorResponse(w, requestID, err)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+			return
+		}
+
+		switch err {
+		case db.ErrInvalidRequest, scheduler.ErrInvalidRequest:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidSchedulingUnit)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+
+		case scheduler.ErrInvalidEndAt:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidEndAt)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+
+		case scheduler.ErrInvalidMaxRuns:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidMaxRuns)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+
+		case scheduler.ErrInvalidJitter:
+			sendBadRequestErrorResponse(w, requestID, reasonInvalidJitter)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+
+		case db.ErrNotFound:
+			sendNotFoundErrorResponse(w)
+			metrics.MetricUpdateTaskNotFoundErrors.Inc()
+
+		case scheduler.ErrTaskFinished:
+			sendConflictErrorResponse(w, requestID, reasonTaskFinished)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+
+		case db.ErrTaskStatusChanged:
+			sendConflictErrorResponse(w, requestID, reasonTaskStatusChanged)
+			metrics.MetricUpdateTaskBadRequests.Inc()
+
+		default:
+			sendInternalServerErrorResponse(w)
+			metrics.MetricUpdateTaskInternalErrors.Inc()
+		}
+		return
+	}
+
+	// Return the updated task information to the caller.
+	err = sendJsonResponse(w, http.StatusOK, updatedTask)
+	if err != nil {
+		schedLogger.Error("Failed to encode JSON response!",
+			zap.String("Request ID: ", requestID),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricUpdateTaskInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricUpdateTaskResponses.Inc()
+}
@@ -41,6 +41,9 @@ func NewScheduledTask(loc *time.Location, deviceID string,
 	}
 
 	newTask.TaskInfo.ServiceID = request.ServiceId
+	if loc != time.UTC {
+		newTask.TaskInfo.Timezone = loc.String()
+	}
 	newTask.TaskInfo.MessageType = request.MessageType
 	newTask.TaskInfo.MessageId = request.MessageId
 
@@ -159,45 +162,114 @@ func (s *ScheduledTask) Cron(cronExpression string,
 			cronExpression)
 	}
 
+	cronSchedule, err = parseCronSpec(withLocation, withSeconds)
+	if err != nil {
+		s.error = wrapOrError(err, ErrCronParseFailure)
+	}
+	s.cronSchedule = cronSchedule
+	s.TaskInfo.Unit = common.Crontab
+	s.TaskInfo.CronSpec = withLocation
+	s.TaskInfo.CronWithSeconds = withSeconds
+	s.TaskInfo.StartImmediately = false
+
+	return s
+}
+
+// Parse the specified cron expression, optionally with a seconds field, into a
+// cron schedule.
+func parseCronSpec(cronSpec string, withSeconds bool) (cron.Schedule, error) {
 	if withSeconds {
 		p := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom |
 			cron.Month | cron.Dow | cron.Descriptor)
-		cronSchedule, err = p.Parse(withLocation)
-	} else {
-		cronSchedule, err = cron.ParseStandard(withLocation)
+		return p.Parse(cronSpec)
 	}
+	return cron.ParseStandard(cronSpec)
+}
 
+// RRule - specifies an iCalendar (RFC 5545) recurrence rule for the task. The
+// recurrence is specified as DTSTART, RRULE and EXDATE content lines separated
+// by whitespace, e.g. "DTSTART:20240102T090000Z RRULE:FREQ=MONTHLY;BYDAY=2TU".
+// If DTSTART is not specified, the recurrence starts now.
+func (s *ScheduledTask) RRule(spec string) *ScheduledTask {
+	lines := strings.Fields(spec)
+	rrule, err := parseRecurrenceSet(lines, s.location, time.Now())
 	if err != nil {
-		s.error = wrapOrError(err, ErrCronParseFailure)
+		schedLogger.Error("Failed to parse the specified recurrence rule!",
+			zap.String("Recurrence rule", spec),
+			zap.Error(err),
+		)
+		s.error = wrapOrError(s.error, err)
+	} else {
+		lines = rrule.withStart(lines)
 	}
-	s.cronSchedule = cronSchedule
-	s.TaskInfo.Unit = common.Crontab
+
+	s.TaskInfo.Unit = common.RRule
+	s.TaskInfo.RRuleSpec = strings.Join(lines, "\n")
 	s.TaskInfo.StartImmediately = false
 
 	return s
 }
 
-// Schedule - requests the scheduler to schedule the task.
-func (s *ScheduledTask) Schedule() (*ScheduledTask, error) {
-	var err error
+// Perform some validation checks on the type of scheduled task being
+// submitted to the scheduler for execution. Returns any errors encountered
+// while building the scheduled task.
+func (s *ScheduledTask) validate() error {
+	// The schedule of a task which failed to parse is incomplete, and would
+	// only be reported as invalid for the same reason.
+	if s.error != nil {
+		return s.error
+	}
 
-	// Perform some validation checks on the type of scheduled task being
-	// submitted to the scheduler for execution.
 	if (len(s.TaskInfo.ScheduledWeekdays) != 0) &&
 		(s.TaskInfo.Unit != common.Weeks) {
 		s.error = wrapOrError(s.error, ErrWeekdayNotSupported)
 	}
 
-	if s.TaskInfo.Unit != common.Crontab && s.TaskInfo.Interval == 0 {
+	if s.TaskInfo.Unit != common.Crontab && s.TaskInfo.Unit != common.RRule &&
+		s.TaskInfo.Interval == 0 {
 		if (s.TaskInfo.Unit != common.Duration) &&
 			(s.TaskInfo.Unit != common.Once) {
 			s.error = wrapOrError(s.error, ErrInvalidInterval)
 		}
 	}
 
+	// Recurrence rules may have ended, through their COUNT or UNTIL.
+	if s.error == nil && s.TaskInfo.Unit == common.RRule {
+		_, ok := nextRunTime(s.TaskInfo, time.Now(), s.location)
+		if !ok {
+			s.error = ErrRRuleNoRuns
+		}
+	}
+
+	firstRun := firstRunTime(s.TaskInfo, time.Now(), s.location)
+
+	// Runs spread over a jitter window longer than the period of the schedule
+	// would overtake the runs which follow them.
+	if s.error == nil && s.TaskInfo.JitterWindow > 0 &&
+		s.TaskInfo.Unit != common.Once {
+		period, ok := shortestPeriod(s.TaskInfo, firstRun, s.location)
+		if ok && s.TaskInfo.JitterWindow > period {
+			s.error = ErrInvalidJitter
+		}
+	}
+
+	// The task must be able to run at least once within the bounds of its
+	// schedule, once the first run is offset within its jitter window.
+	if !s.TaskInfo.EndAt.IsZero() && s.TaskInfo.Unit != common.Once &&
+		jitteredRunTime(s.TaskInfo, firstRun).After(s.TaskInfo.EndAt) {
+		s.error = wrapOrError(s.error, ErrInvalidScheduleBound)
+	}
+
+	return s.error
+}
+
+// Schedule - requests the scheduler to schedule the task.
+func (s *ScheduledTask) Schedule() (*ScheduledTask, error) {
+	var err error
+
 	// If the prior steps while building the scheduled task resulted in errors,
 	// fail scheduling.
-	if s.error != nil {
+	if s.validate() != nil {
 		schedLogger.Error("Failed to schedule the task!",
 			zap.Error(s.error),
 		)
@@ -229,6 +301,18 @@ func (s *ScheduledTask) Schedule() (*ScheduledTask, error) {
 			return nil, err
 		}
 
+		// Record the run of the task in its run history before it is
+		// dispatched, as for scheduled runs, so that the dispatch and the
+		// response from the device are recorded against it.
+		err = s.TaskInfo.CreateTaskRun(time.Now())
+		if err != nil {
+			schedLogger.Error("Failed to record the run of the task!",
+				zap.String("Task ID", s.TaskInfo.TaskID.String()),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
 		// Send the task to the dispatch queue. Tasks on the dispatch
 		// queue are sent to the MQTT broker for delivery to the device.
 		err = queuemgr.Provider.SendDispatchQueueMessage(payload)
@@ -243,7 +327,8 @@ func (s *ScheduledTask) Schedule() (*ScheduledTask, error) {
 	} else {
 		// Create a scheduled run for the task and store it in the database.
 		s.ScheduleInfo.TaskID = s.TaskInfo.TaskID
-		s.ScheduleInfo.NextRun = time.Now()
+		s.ScheduleInfo.NextRun = jitteredRunTime(s.TaskInfo,
+			firstRunTime(s.TaskInfo, time.Now(), s.location))
 
 		err = s.ScheduleInfo.CreateScheduledRun()
 		if err != nil {
@@ -0,0 +1,284 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/queuemgr"
+	"go.uber.org/zap"
+)
+
+var (
+	CancelTaskHandlerFunc        = cancelTask
+	CancelConsignmentHandlerFunc = cancelConsignment
+)
+
+// The number of attempts made to change a task whose status is changed
+// concurrently, for instance by a response from the device.
+const maxTaskChangeAttempts = 3
+
+// Time spent cancelling the tasks of a consignment while handling a request,
+// well within the write timeout of the REST server. The remaining tasks of the
+// consignment are cancelled in the background.
+const consignmentCancelBudget = 3 * time.Second
+
+// ConsignmentCancellation - summary of the tasks processed when cancelling a
+// consignment.
+type ConsignmentCancellation struct {
+	ConsignmentID  string `json:"consignment_id"`
+	TenantID       string `json:"tenant_id"`
+	CancelledCount int    `json:"cancelled_count"`
+	CompletedCount int    `json:"completed_count"`
+	ErrorCount     int    `json:"error_count"`
+
+	// Set if the remaining tasks of the consignment are being cancelled in
+	// the background, in which case the counts only cover the tasks processed
+	// so far.
+	InProgress bool `json:"in_progress,omitempty"`
+}
+
+// Cancel the specified task and ask the device to stop executing it.
+func cancelTask(taskID string, deviceID string) error {
+	task, err := db.GetTaskByID(taskID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	return cancelScheduledTask(task, true)
+}
+
+// Cancel every task in the specified consignment that has not yet finished,
+// along with the rollout of the consignment. Devices are asked to stop
+// executing the cancelled tasks if requested. If cancelling the tasks takes
+// too long, the remaining tasks are cancelled in the background. Returns
+// ErrNotFound if the consignment has no tasks.
+func cancelConsignment(tenantID string, consignmentID string,
+	notifyDevice bool) (*ConsignmentCancellation, error) {
+	summary := &ConsignmentCancellation{
+		ConsignmentID: consignmentID,
+		TenantID:      tenantID,
+	}
+
+	// No tasks are scheduled for the devices in later stages of a rollout of
+	// the consignment once it is cancelled.
+	cancelRollout(tenantID, consignmentID)
+
+	found, nextPage, err := cancelConsignmentPage(summary, nil, notifyDevice)
+	if err != nil {
+		return nil, err
+	}
+	if found == 0 {
+		return nil, db.ErrNotFound
+	}
+
+	deadline := time.Now().Add(consignmentCancelBudget)
+	for len(nextPage) != 0 && time.Now().Before(deadline) {
+		_, nextPage, err = cancelConsignmentPage(summary, nextPage,
+			notifyDevice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(nextPage) != 0 {
+		summary.InProgress = true
+		remaining := *summary
+		go cancelRemainingConsignmentTasks(&remaining, nextPage, notifyDevice)
+		return summary, nil
+	}
+
+	logConsignmentCancellation(summary)
+	return summary, nil
+}
+
+// Cancel the tasks of the consignment from the specified page onwards, in the
+// background. The counts of the specified summary are updated as the tasks are
+// processed.
+func cancelRemainingConsignmentTasks(summary *ConsignmentCancellation,
+	pageState []byte, notifyDevice bool) {
+	var err error
+	for len(pageState) != 0 && schedCtx.Err() == nil {
+		_, pageState, err = cancelConsignmentPage(summary, pageState,
+			notifyDevice)
+		if err != nil {
+			schedLogger.Error("Failed to cancel the remaining tasks of the consignment!",
+				zap.String("Consignment ID", summary.ConsignmentID),
+				zap.String("Tenant ID", summary.TenantID),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+
+	summary.InProgress = len(pageState) != 0
+	logConsignmentCancellation(summary)
+}
+
+// Cancel every task in the specified page of tasks in the consignment that has
+// not yet finished, and update the counts of the specified summary. Returns
+// the number of tasks in the page and the page state of the next page.
+func cancelConsignmentPage(summary *ConsignmentCancellation, pageState []byte,
+	notifyDevice bool) (int, []byte, error) {
+	found, errorCount, nextPage, err := forConsignmentTaskPage(
+		summary.TenantID, summary.ConsignmentID, pageState, 0,
+		func(task *db.Task) {
+			if isFinished(task) {
+				summary.CompletedCount++
+				return
+			}
+
+			err := cancelScheduledTask(task, notifyDevice)
+			if err != nil {
+				summary.ErrorCount++
+				return
+			}
+			summary.CancelledCount++
+		})
+	if err != nil {
+		return 0, nil, err
+	}
+	summary.ErrorCount += errorCount
+
+	return found, nextPage, nil
+}
+
+// Log the summary of the cancellation of a consignment.
+func logConsignmentCancellation(summary *ConsignmentCancellation) {
+	schedLogger.Info("Cancelled the consignment!",
+		zap.String("Consignment ID", summary.ConsignmentID),
+		zap.String("Tenant ID", summary.TenantID),
+		zap.Int("Cancelled", summary.CancelledCount),
+		zap.Int("Completed", summary.CompletedCount),
+		zap.Int("Failures", summary.ErrorCount),
+		zap.Bool("Interrupted", summary.InProgress),
+	)
+}
+
+// Invoke the specified function for each task in the specified consignment.
+// Tasks which have been removed are skipped. Returns the number of tasks which
+// could not be retrieved.
+func forEachConsignmentTask(tenantID string, consignmentID string,
+	fn func(task *db.Task)) (int, error) {
+	var (
+		pageState []byte
+		err       error
+	)
+
+	errorCount := 0
+	for {
+		var pageErrors int
+		_, pageErrors, pageState, err = forConsignmentTaskPage(tenantID,
+			consignmentID, pageState, 0, fn)
+		errorCount += pageErrors
+		if err != nil {
+			return errorCount, err
+		}
+
+		if len(pageState) == 0 {
+			return errorCount, nil
+		}
+	}
+}
+
+// Invoke the specified function for each task in the specified page of tasks
+// in the specified consignment. Tasks which have been removed are skipped.
+// Returns the number of tasks in the page, the number of tasks which could not
+// be retrieved and the page state of the next page.
+func forConsignmentTaskPage(tenantID string, consignmentID string,
+	pageState []byte, pageSize int,
+	fn func(task *db.Task)) (int, int, []byte, error) {
+	foundConsignments, nextPage, err := db.GetTasksForConsignment(tenantID,
+		consignmentID, nil, pageState, pageSize)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	errorCount := 0
+	for _, item := range foundConsignments {
+		task, err := db.GetTaskByID(item.TaskID.String(),
+			item.DeviceID.String())
+		if err == db.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			errorCount++
+			continue
+		}
+
+		fn(task)
+	}
+
+	return len(foundConsignments), errorCount, nextPage, nil
+}
+
+// Check if the task has finished and will not be dispatched again. Recurring
+// tasks are never finished until they are cancelled or reach the end of their
+// bounded schedule.
+func isFinished(task *db.Task) bool {
+	switch task.Status {
+	case db.TaskStatusCancelled.String(),
+		db.TaskStatusScheduleExhausted.String():
+		return true
+
+	case db.TaskStatusCompleted.String(), db.TaskStatusFailed.String(),
+		db.TaskStatusTimedOut.String():
+		if task.IsScheduleEnded() {
+			return true
+		}
+		_, recurs := nextRunTime(task, time.Now(), taskLocation(task))
+		return !recurs
+	}
+	return false
+}
+
+// Apply the specified change to the task. If the status of the task was changed
+// concurrently, the task is read again and the change is retried, up to the
+// maximum number of attempts.
+func changeTask(task *db.Task, change func(task *db.Task) error) error {
+	for attempt := 1; ; attempt++ {
+		err := change(task)
+		if err != db.ErrTaskStatusChanged || attempt == maxTaskChangeAttempts {
+			return err
+		}
+
+		current, err := db.GetTaskByID(task.TaskID.String(),
+			task.DeviceID.String())
+		if err != nil {
+			return err
+		}
+		*task = *current
+	}
+}
+
+// Cancel the task and, if requested, ask the device to stop executing it. The
+// cancellation message is sent to the device through the dispatch queue, on
+// the same topic as the task itself.
+func cancelScheduledTask(task *db.Task, notifyDevice bool) error {
+	err := changeTask(task, db.CancelTask)
+	if err != nil {
+		return err
+	}
+
+	if notifyDevice {
+		payload, err := task.MarshalCancelMessage()
+		if err != nil {
+			return err
+		}
+
+		err = queuemgr.Provider.SendDispatchQueueMessage(payload)
+		if err != nil {
+			schedLogger.Error("Failed to send the cancellation message to the device!",
+				zap.String("Task ID", task.TaskID.String()),
+				zap.String("Device ID", task.DeviceID.String()),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+
+	schedLogger.Info("Cancelled the task!",
+		zap.String("Task ID", task.TaskID.String()),
+		zap.String("Device ID", task.DeviceID.String()),
+	)
+	return nil
+}
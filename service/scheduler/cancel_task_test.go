@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestIsFinished(t *testing.T) {
+	tests := []struct {
+		unit     common.SchedulingUnit
+		status   db.TaskStatus
+		finished bool
+	}{
+		{common.Once, db.TaskStatusCompleted, true},
+		{common.Once, db.TaskStatusTimedOut, true},
+		{common.Once, db.TaskStatusDispatched, false},
+		{common.Once, db.TaskStatusPendingRetry, false},
+		{common.Hours, db.TaskStatusCompleted, false},
+		{common.Hours, db.TaskStatusCancelled, true},
+		{common.Hours, db.TaskStatusScheduleExhausted, true},
+	}
+
+	for _, test := range tests {
+		task := &db.Task{Unit: test.unit, Interval: 1,
+			Status: test.status.String()}
+		if got := isFinished(task); got != test.finished {
+			t.Errorf("Unexpected result %v for a task with unit %d and status %s\n",
+				got, test.unit, test.status)
+		}
+	}
+}
+
+func TestIsFinished_ScheduleEnded(t *testing.T) {
+	// A bounded recurring task with no further runs has finished once its
+	// final run has.
+	task := &db.Task{Unit: common.Hours, Interval: 1, MaxRuns: 3, RunCount: 3,
+		Status: db.TaskStatusFailed.String()}
+	if !isFinished(task) {
+		t.Errorf("Expected a task at the end of its schedule to be finished\n")
+	}
+
+	task.Status = db.TaskStatusDispatched.String()
+	if isFinished(task) {
+		t.Errorf("Expected a task with its final run in progress to not be finished\n")
+	}
+}
@@ -1,27 +1,52 @@
 package scheduler
 
 import (
+	"sync"
 	"time"
 
 	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
 	"github.com/hpinc/krypton-scheduler/service/queuemgr"
 	"go.uber.org/zap"
 )
 
 const (
-	schedulerExecutionQuantum = 1 * time.Minute
+	// The default interval between passes of the scheduler daemon.
+	defaultExecutionQuantum = 1 * time.Minute
 )
 
-func runSchedulerDaemon() {
-	var (
-		foundRuns    []*db.ScheduledRun
-		nextPage     []byte
-		err          error
-		runPartition string
-	)
+// Return the interval between passes of the scheduler daemon. Runs due before
+// the next pass are fired at their exact time by the near-term run queue.
+func getExecutionQuantum() time.Duration {
+	if schedConfig.ExecutionQuantum <= 0 {
+		return defaultExecutionQuantum
+	}
+	return schedConfig.ExecutionQuantum
+}
+
+// Return the oldest run partition checked for runs left over from an outage of
+// the scheduler, for a scheduler starting up at the specified time.
+func getCatchupStart(now time.Time) time.Time {
+	return now.UTC().Truncate(db.RunPartitionPeriod).
+		Add(-time.Duration(schedConfig.CatchupHours) * db.RunPartitionPeriod)
+}
 
+func runSchedulerDaemon() {
 	schedLogger.Info("Starting the scheduler daemon!")
 
+	// Multiple scheduler instances may be running. Scheduled runs are only
+	// dispatched by the instance holding the scheduler daemon lease.
+	initLeaseOwner()
+	defer releaseSchedulerLease()
+
+	// Runs left over in the run partitions of previous hours, for instance
+	// due to an outage of the scheduler, are picked up on startup. The
+	// scheduler stops checking older partitions once they have been drained.
+	oldestPartition := getCatchupStart(time.Now())
+
+	// Fire the runs due before the next pass of the scheduler daemon.
+	go nearTermRuns.run(schedCtx)
+
 	for {
 		// Check if the scheduler daemon goroutine needs to stop execution, if
 		// the service is shutting down.
@@ -30,80 +55,345 @@ func runSchedulerDaemon() {
 			return
 		}
 
-		runPartition = db.GetRunPartition(time.Now())
-
-		for {
-			// Retrieve a page worth of scheduled runs that are ready for execution
-			// from the scheduled runs table.
-			foundRuns, nextPage, err = db.GetScheduledRuns(runPartition, nextPage)
-			if err != nil {
-				schedLogger.Error("Failed to query next run tasks from the scheduler database!",
-					zap.Error(err),
-				)
-				break
-			}
+		now := time.Now()
+		quantum := getExecutionQuantum()
+		nextPass := now.Truncate(quantum).Add(quantum)
+		dueBy := now.Add(schedConfig.Lookahead)
+		loadBy := nextPass.Add(schedConfig.Lookahead)
+		nearTermRuns.setHorizon(loadBy, schedConfig.Lookahead,
+			now.Add(-quantum))
 
-			for _, item := range foundRuns {
-				// Check if the scheduler daemon goroutine needs to stop execution, if
-				// the service is shutting down.
-				if schedCtx.Err() != nil {
-					schedLogger.Info("Stopping the scheduler daemon! Context has been canceled")
-					return
+		if holdsSchedulerLease() {
+			// Process the runs in each run partition starting from the
+			// oldest partition that may still contain runs, up to the
+			// partition for the start of the next pass. Runs due before the
+			// next pass are queued to be fired at their exact time.
+			nextOldestPartition := now.UTC().Truncate(db.RunPartitionPeriod)
+			for partition := oldestPartition; !partition.After(loadBy); partition = partition.Add(db.RunPartitionPeriod) {
+				drained, ok := processRunPartition(db.GetRunPartition(partition),
+					dueBy, loadBy)
+				if !drained && partition.Before(nextOldestPartition) {
+					nextOldestPartition = partition
 				}
-
-				schedLogger.Debug("Retrieved a candidate task for execution from database!",
-					zap.String("Task ID", item.TaskID.String()),
-					zap.Time("Next Run", item.NextRun),
-				)
-
-				// Retrieve information about the task such as its payload
-				// from the database.
-				task, err := db.GetTaskByID(item.TaskID.String(),
-					item.DeviceID.String())
-				if err != nil {
-					schedLogger.Error("Failed to retrieve task information!",
-						zap.String("Task ID", item.TaskID.String()),
-						zap.Error(err),
-					)
-					continue
+				if !ok {
+					break
 				}
+			}
+			oldestPartition = nextOldestPartition
+		}
 
-				// Encode the task information to prepare for posting to the
-				// dispatch queue.
-				payload, err := task.MarshalServiceMessage()
-				if err != nil {
-					schedLogger.Error("Failed to encode the message for delivery to the device!",
-						zap.String("Task ID", task.TaskID.String()),
-						zap.Error(err),
-					)
-					continue
-				}
+		// Wait for the start of the next scheduler execution quantum.
+		select {
+		case <-time.After(time.Until(nextPass)):
 
-				// Send the task to the dispatch queue. Tasks on the dispatch
-				// queue are sent to the MQTT broker for delivery to the device.
-				err = queuemgr.Provider.SendDispatchQueueMessage(payload)
-				if err != nil {
-					schedLogger.Error("Failed to dispatch the scheduled task to the device!",
-						zap.String("Task ID", item.TaskID.String()),
-						zap.String("Device ID", item.DeviceID.String()),
-						zap.Error(err),
-					)
-					continue
-				}
+		case <-schedCtx.Done():
+			schedLogger.Info("Received signal to stop the scheduler daemon!")
+			return
+		}
+	}
+}
+
+// Dispatch the runs in the specified run partition that are due by the
+// specified time, and queue the runs due after that time up to the specified
+// load time to be fired at their exact time. The shards of the run partition
+// are processed in parallel. Returns whether all due runs in the partition were
+// processed and false in the second return value if the scheduler daemon is
+// stopping or this scheduler instance no longer holds the scheduler daemon
+// lease.
+func processRunPartition(runPartition string, dueBy time.Time,
+	loadBy time.Time) (bool, bool) {
+	var wg sync.WaitGroup
+
+	drained := make([]bool, db.RunPartitionShards)
+	ok := make([]bool, db.RunPartitionShards)
+
+	for shard := 0; shard < db.RunPartitionShards; shard++ {
+		wg.Add(1)
+		go func(shard int) {
+			defer wg.Done()
+			drained[shard], ok[shard] = processRunShard(runPartition, shard,
+				dueBy, loadBy)
+		}(shard)
+	}
+	wg.Wait()
+
+	allDrained, allOk := true, true
+	for shard := 0; shard < db.RunPartitionShards; shard++ {
+		allDrained = allDrained && drained[shard]
+		allOk = allOk && ok[shard]
+	}
+	return allDrained, allOk
+}
+
+// Process the runs in the specified shard of the specified run partition as
+// for processRunPartition. Returns values as for processRunPartition.
+func processRunShard(runPartition string, shard int, dueBy time.Time,
+	loadBy time.Time) (bool, bool) {
+	var (
+		foundRuns []*db.ScheduledRun
+		nextPage  []byte
+		err       error
+	)
 
+	drained := true
+	for {
+		// Retrieve a page worth of scheduled runs that are ready for execution
+		// from the scheduled runs table.
+		foundRuns, nextPage, err = db.GetScheduledRuns(runPartition, shard,
+			loadBy, nextPage)
+		if err != nil {
+			schedLogger.Error("Failed to query next run tasks from the scheduler database!",
+				zap.String("Run partition", runPartition),
+				zap.Int("Shard", shard),
+				zap.Error(err),
+			)
+			return false, true
+		}
+
+		for _, item := range foundRuns {
+			// Check if the scheduler daemon goroutine needs to stop execution, if
+			// the service is shutting down.
+			if schedCtx.Err() != nil {
+				schedLogger.Info("Stopping the scheduler daemon! Context has been canceled")
+				return false, false
 			}
 
-			if len(nextPage) == 0 {
-				break
+			// Make sure that the lease is still held by this instance,
+			// before dispatching the run.
+			if !holdsSchedulerLease() {
+				return false, false
+			}
+
+			if !processScheduledRun(item, dueBy) {
+				drained = false
 			}
 		}
 
-		select {
-		case <-time.After(schedulerExecutionQuantum):
+		if len(nextPage) == 0 {
+			return drained, true
+		}
+	}
+}
 
-		case <-schedCtx.Done():
-			schedLogger.Info("Received signal to stop the scheduler daemon!")
-			return
+// Dispatch the specified scheduled run if it is due by the specified time, or
+// queue it to be fired at its exact time. Runs already queued or fired are
+// skipped. Once dispatched, the next run of a recurring task is queued if it is
+// due before the next pass of the scheduler daemon. Returns false if the run
+// could not be processed and was left in place to be retried.
+func processScheduledRun(item *db.ScheduledRun, dueBy time.Time) bool {
+	if nearTermRuns.isKnown(item) {
+		return true
+	}
+	if item.NextRun.After(dueBy) {
+		nearTermRuns.add(item)
+		return true
+	}
+
+	lastRun := item.NextRun
+	if !dispatchScheduledRun(item) {
+		return false
+	}
+	if item.NextRun.After(lastRun) {
+		nearTermRuns.add(item)
+	}
+	return true
+}
+
+// Dispatch the specified scheduled run to the device and schedule the next run
+// of the task. Returns false if the run could not be processed and was left in
+// place to be retried.
+func dispatchScheduledRun(item *db.ScheduledRun) bool {
+	schedLogger.Debug("Retrieved a candidate task for execution from database!",
+		zap.String("Task ID", item.TaskID.String()),
+		zap.Time("Next Run", item.NextRun),
+	)
+
+	// Retrieve information about the task such as its payload
+	// from the database.
+	task, err := db.GetTaskByID(item.TaskID.String(),
+		item.DeviceID.String())
+	if err == db.ErrNotFound {
+		// The task has been removed, so its scheduled run is removed too.
+		schedLogger.Info("Removing scheduled run for a task that no longer exists!",
+			zap.String("Task ID", item.TaskID.String()),
+		)
+		err = item.RemoveScheduledRun()
+		return err == nil
+	}
+	if err != nil {
+		schedLogger.Error("Failed to retrieve task information!",
+			zap.String("Task ID", item.TaskID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	// Only the run recorded as the next run of the task is dispatched. Other
+	// runs were superseded, for instance by an update of the schedule of the
+	// task, or were left behind while the task was rescheduled. Runs copied
+	// from the original scheduled runs table have no next run recorded.
+	if !item.IsRetry && !task.NextRun.IsZero() &&
+		task.NextRun.UnixMilli() != item.NextRun.UnixMilli() {
+		schedLogger.Info("Removing a superseded scheduled run for the task!",
+			zap.String("Task ID", item.TaskID.String()),
+			zap.Time("Run", item.NextRun),
+			zap.Time("Next Run", task.NextRun),
+		)
+		err = item.RemoveRunEntry()
+		return err == nil
+	}
+
+	// Paused tasks are not dispatched. The run is removed and the next run of
+	// the task is scheduled again when the task is resumed, while pending
+	// retries are dropped.
+	if task.Status == db.TaskStatusPaused.String() {
+		schedLogger.Info("Removing scheduled run for a paused task!",
+			zap.String("Task ID", item.TaskID.String()),
+		)
+		if item.IsRetry {
+			err = item.RemoveScheduledRun()
+		} else {
+			err = item.RemoveRunEntry()
 		}
+		return err == nil
 	}
+
+	// Cancelled tasks are not dispatched again. Their runs are normally
+	// removed when the task is cancelled, but a run may have been scheduled
+	// concurrently.
+	if task.Status == db.TaskStatusCancelled.String() {
+		schedLogger.Info("Removing scheduled run for a cancelled task!",
+			zap.String("Task ID", item.TaskID.String()),
+		)
+		err = item.RemoveScheduledRun()
+		return err == nil
+	}
+
+	// Encode the task information to prepare for posting to the
+	// dispatch queue.
+	payload, err := task.MarshalServiceMessage()
+	if err != nil {
+		schedLogger.Error("Failed to encode the message for delivery to the device!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	// Runs which were missed are handled as per the misfire policy of the
+	// task. Runs of the task which follow are scheduled after the current time,
+	// unless every missed run is to be run.
+	now := time.Now()
+	misfire := ""
+	nextRunAfterTime := now
+	if !item.IsRetry && isMissedRun(item, now) {
+		misfire = misfireDecision(taskMisfirePolicy(task), item.NextRun, now)
+		schedLogger.Info("Handling a missed run of the task!",
+			zap.String("Task ID", item.TaskID.String()),
+			zap.Time("Next Run", item.NextRun),
+			zap.String("Misfire Decision", misfire),
+		)
+	}
+	if misfire == db.MisfireDecisionSkipped {
+		_ = task.CreateSkippedTaskRun(item.NextRun)
+		if !scheduleNextRun(task, item, now) {
+			return false
+		}
+
+		// A task whose final run was skipped has no run left to complete.
+		if task.IsScheduleEnded() {
+			_ = db.MarkTaskScheduleExhausted(task)
+		}
+		return true
+	}
+	if misfire == db.MisfireDecisionFired {
+		nextRunAfterTime = item.NextRun
+	}
+
+	// Record a new run of the task in its run history. Retries are further
+	// attempts of the current run. The run is recorded before it is
+	// dispatched, so that the dispatch and the response from the device are
+	// recorded against it, and is not recorded again if its dispatch fails and
+	// is attempted again.
+	if !item.IsRetry {
+		err = task.CreateMissedTaskRun(item.NextRun, misfire)
+		if err != nil {
+			return false
+		}
+	}
+
+	// Send the task to the dispatch queue. Tasks on the dispatch
+	// queue are sent to the MQTT broker for delivery to the device.
+	err = queuemgr.Provider.SendDispatchQueueMessage(payload)
+	if err != nil {
+		schedLogger.Error("Failed to dispatch the scheduled task to the device!",
+			zap.String("Task ID", item.TaskID.String()),
+			zap.String("Device ID", item.DeviceID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	// Record how late the run was dispatched, with respect to the time the run
+	// was due rather than the time it was picked up within the lookahead.
+	metrics.MetricRunDispatchLateness.Observe(
+		time.Since(item.NextRun).Seconds())
+
+	// Retries are run once, after which the scheduled run is removed.
+	if item.IsRetry {
+		err = item.RemoveScheduledRun()
+		return err == nil
+	}
+
+	// Compute the next run of the task and move the scheduled run
+	// forward, or remove it if the task does not recur.
+	return scheduleNextRun(task, item, nextRunAfterTime)
+}
+
+// Schedule the next run of the specified task after its scheduled run has been
+// dispatched, skipping any runs due before the specified instant. Tasks that
+// do not recur have their scheduled run removed. Tasks that have reached the
+// end of their bounded schedule have their schedule ended, so that they are
+// marked as exhausted once their final run completes.
+func scheduleNextRun(task *db.Task, run *db.ScheduledRun, after time.Time) bool {
+	nextRun, ok := nextRecurrenceAfter(task, run.NextRun, after,
+		taskLocation(task))
+	if ok && !withinScheduleBounds(task, nextRun, task.RunCount) {
+		schedLogger.Info("The task has reached the end of its schedule!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.Int("Run Count", task.RunCount),
+		)
+		return run.EndSchedule(task) == nil
+	}
+	if !ok {
+		err := run.RemoveScheduledRun()
+		if err != nil {
+			schedLogger.Error("Failed to remove the scheduled run for the task!",
+				zap.String("Task ID", task.TaskID.String()),
+				zap.Error(err),
+			)
+			return false
+		}
+		return true
+	}
+
+	err := run.Reschedule(nextRun)
+	if err == db.ErrNotFound {
+		// The task was removed while its run was being dispatched.
+		return true
+	}
+	if err != nil {
+		schedLogger.Error("Failed to schedule the next run for the task!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.Time("Next Run", nextRun),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	schedLogger.Debug("Scheduled the next run for the task!",
+		zap.String("Task ID", task.TaskID.String()),
+		zap.Time("Next Run", nextRun),
+	)
+	return true
 }
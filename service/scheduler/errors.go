@@ -9,7 +9,7 @@ import (
 var (
 	ErrNotScheduledWeekday              = errors.New("task not scheduled weekly on a weekday")
 	ErrUnsupportedTimeFormat            = errors.New("the given time format is not supported")
-	ErrInvalidScheduleType              = errors.New("schedule type must be either Every or At")
+	ErrInvalidScheduleType              = errors.New("schedule type must be one of every, at, cron or rrule")
 	ErrInvalidSchedulingUnit            = errors.New("invalid scheduling unit requested")
 	ErrInvalidInterval                  = errors.New(".Every() interval must be greater than 0")
 	ErrInvalidIntervalType              = errors.New(".Every() interval must be of type int, time.Duration, or string")
@@ -23,8 +23,63 @@ var (
 	ErrInvalidServiceID                 = errors.New("the specified service ID is invalid")
 	ErrInvalidMessageType               = errors.New("the specified message type is invalid")
 	ErrInvalidRequest                   = errors.New("invalid request")
+	ErrInvalidRetryPolicy               = errors.New("the specified retry policy is invalid")
+	ErrInvalidResponseTimeout           = errors.New("the specified response timeout is invalid")
+	ErrTaskFinished                     = errors.New("the task has already finished")
+	ErrInvalidMisfirePolicy             = errors.New("the specified misfire policy is invalid")
+	ErrInvalidTimezone                  = errors.New("the specified timezone is invalid")
+	ErrInvalidPreviewRunCount           = errors.New("the number of runs to preview must be between 1 and 100")
+	ErrMissingSchedulingUnit            = errors.New("a scheduling unit must be specified with the interval")
+	ErrUnexpectedScheduleToken          = errors.New("unexpected token in schedule")
+	ErrInvalidScheduleBound             = errors.New("starting and until must be RFC3339 times, with until after starting, for must be a positive number of times and within must be a positive duration")
+	ErrRRuleParseFailure                = errors.New("specified recurrence rule could not be parsed")
+	ErrRRuleNoRuns                      = errors.New("the specified recurrence rule has no runs after the current time")
+	ErrInvalidEndAt                     = errors.New("the specified end time must be an RFC3339 time in the future")
+	ErrInvalidMaxRuns                   = errors.New("the specified maximum number of runs must not be negative")
+	ErrInvalidJitter                    = errors.New("the specified jitter must be a positive duration no longer than the period of the schedule")
+	ErrInvalidRolloutPolicy             = errors.New("the specified rollout policy is invalid")
+	ErrRolloutExists                    = errors.New("a rollout already exists for the consignment")
 )
 
+// ScheduleError - describes an error encountered while parsing a schedule
+// string, along with the token of the schedule string that caused the error.
+type ScheduleError struct {
+	// The token of the schedule string that could not be parsed. Empty if
+	// the schedule string ended where a token was expected.
+	Token string
+
+	// The offset of the token from the start of the schedule string.
+	Position int
+
+	// The error encountered while parsing the token.
+	Err error
+
+	// The forms of schedule strings supported by the scheduler.
+	SupportedForms []string
+}
+
+// Create a schedule error for the specified token of a schedule string.
+func newScheduleError(token scheduleToken, err error) *ScheduleError {
+	return &ScheduleError{
+		Token:          token.text,
+		Position:       token.position,
+		Err:            err,
+		SupportedForms: SupportedScheduleForms,
+	}
+}
+
+func (e *ScheduleError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s: unexpected end of schedule at position %d",
+			e.Err, e.Position)
+	}
+	return fmt.Sprintf("%s: %q at position %d", e.Err, e.Token, e.Position)
+}
+
+func (e *ScheduleError) Unwrap() error {
+	return e.Err
+}
+
 // Wrap the existing error or set to the specified error.
 func wrapOrError(toWrap error, err error) error {
 	if toWrap != nil && !errors.Is(err, toWrap) {
@@ -12,11 +12,14 @@ var (
 	schedLogger *zap.Logger
 	schedCtx    context.Context
 	cancelFunc  context.CancelFunc
+	schedConfig *config.SchedulerConfig
 )
 
 // Initialize the scheduler.
 func Init(logger *zap.Logger, cfgMgr *config.ConfigMgr) error {
 	schedLogger = logger
+	schedConfig = cfgMgr.GetSchedulerConfig()
+	initRetryPolicies(cfgMgr)
 
 	schedCtx, cancelFunc = context.WithCancel(context.Background())
 
@@ -36,6 +39,13 @@ func Init(logger *zap.Logger, cfgMgr *config.ConfigMgr) error {
 	// Start off a goroutine that schedules tasks.
 	go runSchedulerDaemon()
 
+	// Start off a goroutine that times out tasks for which devices did not
+	// respond in time.
+	go runResponseTimeoutSweeper()
+
+	// Start off a goroutine that advances staged rollouts of consignments.
+	go runRolloutAdvancer()
+
 	schedLogger.Info("Starting the scheduler engine!")
 	return nil
 }
@@ -0,0 +1,23 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// Initialize logging for the test run.
+	logger, err := zap.NewProduction(zap.AddCaller())
+	if err != nil {
+		fmt.Println("Failed to intialize structured logging for the scheduler tests!")
+		os.Exit(2)
+	}
+	schedLogger = logger
+
+	retCode := m.Run()
+	_ = schedLogger.Sync()
+	os.Exit(retCode)
+}
@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"go.uber.org/zap"
+)
+
+// Jitter - specifies the window following each time in the schedule of the
+// task within which the run of the task is spread, as a duration string (e.g.
+// "2h"). This takes precedence over any window specified in the schedule. The
+// window must not be longer than the period of the schedule, which is checked
+// once the schedule is complete.
+func (s *ScheduledTask) Jitter(jitter string) *ScheduledTask {
+	if jitter == "" {
+		return s
+	}
+
+	window, err := parseJitter(jitter)
+	if err != nil {
+		schedLogger.Error("Invalid jitter specified!",
+			zap.String("Specified jitter", jitter),
+		)
+		s.error = wrapOrError(s.error, err)
+		return s
+	}
+
+	s.TaskInfo.JitterWindow = window
+	return s
+}
+
+// Parse the jitter window of a task specified in a request. Returns zero if no
+// jitter is specified.
+func parseJitter(jitter string) (time.Duration, error) {
+	if jitter == "" {
+		return 0, nil
+	}
+
+	window, err := time.ParseDuration(jitter)
+	if err != nil || window <= 0 {
+		return 0, ErrInvalidJitter
+	}
+	return window, nil
+}
+
+// The number of successive times in the schedule of a task that are inspected
+// to find the shortest period of the schedule.
+const schedulePeriodSamples = 16
+
+// Return the shortest interval between successive times in the schedule of the
+// task, starting at the specified time in its schedule. Schedules which are not
+// periodic, such as those run on specific times of day or days of the month,
+// are sampled. Returns false if the task does not recur.
+func shortestPeriod(task *db.Task, start time.Time,
+	loc *time.Location) (time.Duration, bool) {
+	period, ok := fixedPeriod(task)
+	if ok {
+		return period, true
+	}
+
+	var shortest time.Duration
+	prevRun := start
+	for i := 0; i < schedulePeriodSamples; i++ {
+		nextRun, ok := nextRunTime(task, prevRun, loc)
+		if !ok || !nextRun.After(prevRun) {
+			break
+		}
+		if shortest == 0 || nextRun.Sub(prevRun) < shortest {
+			shortest = nextRun.Sub(prevRun)
+		}
+		prevRun = nextRun
+	}
+	return shortest, shortest > 0
+}
+
+// Return the offset of the runs of the task from the times in its schedule.
+// The offset is derived from a hash of the device ID, so that each device is
+// assigned a stable slot within the jitter window of the task, and the runs
+// of a task scheduled for many devices are spread evenly across the window.
+// Offsets are whole seconds, unless the window is shorter than a second.
+func jitterOffset(task *db.Task) time.Duration {
+	if task.JitterWindow <= 0 {
+		return 0
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(task.DeviceID.Bytes())
+
+	slot := time.Second
+	if task.JitterWindow < slot {
+		slot = time.Nanosecond
+	}
+	slots := uint64(task.JitterWindow / slot)
+	return time.Duration(hash.Sum64()%slots) * slot
+}
+
+// Return the time at which the run of the task scheduled at the specified time
+// in its schedule is dispatched, once offset within its jitter window.
+func jitteredRunTime(task *db.Task, scheduled time.Time) time.Time {
+	return scheduled.Add(jitterOffset(task))
+}
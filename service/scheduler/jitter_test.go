@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+// Return a device ID derived from the specified number.
+func testDeviceID(n int) gocql.UUID {
+	var id gocql.UUID
+	binary.BigEndian.PutUint64(id[8:], uint64(n)*0x9e3779b97f4a7c15)
+	return id
+}
+
+func TestParseSchedule_Within(t *testing.T) {
+	task := parseTestSchedule(t, "at 02:00 within 2h")
+	if task.JitterWindow != 2*time.Hour {
+		t.Errorf("Expected a jitter window of 2h, got %v\n", task.JitterWindow)
+	}
+
+	for _, schedule := range []string{"at 02:00 within soon",
+		"at 02:00 within -1h", "every 1 day within"} {
+		_, err := parseSchedule(schedule, time.UTC)
+		if !errors.Is(err, ErrInvalidScheduleBound) {
+			t.Errorf("%q: expected an invalid schedule bound error, got %v\n",
+				schedule, err)
+		}
+	}
+}
+
+func TestJitterOffset(t *testing.T) {
+	window := 2 * time.Hour
+	buckets := make([]int, 8)
+	devices := 1000
+
+	for i := 0; i < devices; i++ {
+		task := &db.Task{DeviceID: testDeviceID(i), JitterWindow: window}
+		offset := jitterOffset(task)
+		if offset < 0 || offset >= window || offset != jitterOffset(task) {
+			t.Fatalf("Unexpected offset %v for device %v\n", offset,
+				task.DeviceID)
+		}
+		buckets[offset*time.Duration(len(buckets))/window]++
+	}
+
+	// The offsets are spread evenly across the window.
+	for i, count := range buckets {
+		if count < devices/len(buckets)/2 {
+			t.Errorf("Too few devices (%d) in slot %d of the window: %v\n",
+				count, i, buckets)
+		}
+	}
+
+	if jitterOffset(&db.Task{DeviceID: testDeviceID(1)}) != 0 {
+		t.Errorf("Expected no offset without a jitter window\n")
+	}
+}
+
+func TestNextRunAfter_Jitter(t *testing.T) {
+	task := parseTestSchedule(t, "at 02:00 within 2h")
+	task.DeviceID = testDeviceID(42)
+	offset := jitterOffset(task)
+
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	firstRun := jitteredRunTime(task, firstRunTime(task, start, time.UTC))
+	expected := time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC).Add(offset)
+	if !firstRun.Equal(expected) {
+		t.Errorf("Expected the first run at %v, got %v\n", expected, firstRun)
+	}
+
+	// Subsequent runs keep the offset of the device.
+	nextRun, ok := nextRunAfter(task, firstRun, firstRun, time.UTC)
+	if !ok || !nextRun.Equal(expected.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the next run at %v, got %v\n",
+			expected.AddDate(0, 0, 1), nextRun)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	s := &ScheduledTask{location: time.UTC, TaskInfo: &db.Task{}}
+	s.ParseSchedule("at 02:00 within 2h").Jitter("30m")
+	if s.error != nil || s.TaskInfo.JitterWindow != 30*time.Minute {
+		t.Errorf("Expected the jitter to take precedence, got %v (%v)\n",
+			s.TaskInfo.JitterWindow, s.error)
+	}
+
+	s = &ScheduledTask{location: time.UTC, TaskInfo: &db.Task{}}
+	s.ParseSchedule("at 02:00").Jitter("0s")
+	if s.error != ErrInvalidJitter {
+		t.Errorf("Expected an invalid jitter error, got %v\n", s.error)
+	}
+}
+
+func TestJitter_LongerThanPeriod(t *testing.T) {
+	for _, schedule := range []string{"every 1 hour within 3h",
+		"at 01:00;03:00 within 3h"} {
+		_, err := parseSchedule(schedule, time.UTC)
+		if err != ErrInvalidJitter {
+			t.Errorf("%q: expected an invalid jitter error, got %v\n",
+				schedule, err)
+		}
+	}
+
+	_, err := parseSchedule("every 1 hour within 1h", time.UTC)
+	if err != nil {
+		t.Errorf("Expected a jitter window of one period, got %v\n", err)
+	}
+}
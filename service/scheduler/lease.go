@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"go.uber.org/zap"
+)
+
+const (
+	// Name of the lease that must be held by a scheduler instance in order to
+	// dispatch scheduled runs.
+	schedulerDaemonLeaseName = "scheduler_daemon"
+
+	// Lease duration used if none is configured.
+	defaultLeaseDuration = 2 * defaultExecutionQuantum
+)
+
+var (
+	// Identifies this scheduler instance as the owner of leases.
+	leaseOwner string
+
+	// The time at which the lease held by this scheduler instance expires.
+	leaseExpiry time.Time
+
+	// Serializes access to the lease from the goroutines processing shards.
+	leaseMutex sync.Mutex
+)
+
+// Generate the identifier used by this scheduler instance to claim leases.
+func initLeaseOwner() {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "scheduler"
+	}
+	leaseOwner = fmt.Sprintf("%s/%s", hostname, uuid.NewString())
+}
+
+func getLeaseDuration() time.Duration {
+	if schedConfig.LeaseDuration <= 0 {
+		return defaultLeaseDuration
+	}
+	return schedConfig.LeaseDuration
+}
+
+// Check if this scheduler instance holds the scheduler daemon lease. The lease
+// is claimed if it is available and renewed once half of its duration has
+// elapsed, so that it does not expire while scheduled runs are dispatched.
+func holdsSchedulerLease() bool {
+	leaseMutex.Lock()
+	defer leaseMutex.Unlock()
+
+	leaseDuration := getLeaseDuration()
+	if time.Until(leaseExpiry) > leaseDuration/2 {
+		return true
+	}
+
+	lease, acquired, err := db.AcquireLease(schedulerDaemonLeaseName,
+		leaseOwner, leaseDuration)
+	if err != nil {
+		schedLogger.Error("Failed to acquire the scheduler daemon lease!",
+			zap.Error(err),
+		)
+		return time.Now().Before(leaseExpiry)
+	}
+
+	if !acquired {
+		if !leaseExpiry.IsZero() {
+			schedLogger.Info("Scheduler daemon lease is now held by another instance!",
+				zap.String("Owner", lease.Owner),
+			)
+			leaseExpiry = time.Time{}
+		}
+		return false
+	}
+
+	if leaseExpiry.IsZero() {
+		schedLogger.Info("Acquired the scheduler daemon lease!",
+			zap.String("Owner", leaseOwner),
+		)
+	}
+	leaseExpiry = lease.ExpiresAt
+	return true
+}
+
+// Release the scheduler daemon lease if it is held by this scheduler instance,
+// so that another instance can take over dispatching scheduled runs.
+func releaseSchedulerLease() {
+	leaseMutex.Lock()
+	defer leaseMutex.Unlock()
+
+	if leaseExpiry.IsZero() {
+		return
+	}
+
+	err := db.ReleaseLease(schedulerDaemonLeaseName, leaseOwner)
+	if err != nil {
+		schedLogger.Error("Failed to release the scheduler daemon lease!",
+			zap.Error(err),
+		)
+	}
+	leaseExpiry = time.Time{}
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+// MisfirePolicy - determines how the runs of a task which were missed, for
+// instance while the task was paused or the scheduler was down, are handled.
+type MisfirePolicy string
+
+const (
+	// Run the task once as soon as possible in place of all the missed runs,
+	// then continue as per its schedule.
+	MisfireFireOnce MisfirePolicy = "fire_once"
+
+	// Run the task once for every missed run, one after another, then
+	// continue as per its schedule.
+	MisfireFireAll MisfirePolicy = "fire_all"
+
+	// Skip the missed runs and run the task at its next scheduled time.
+	MisfireSkip MisfirePolicy = "skip"
+
+	// Run the task once as soon as possible if the missed run was scheduled
+	// within the duration following the policy name (e.g. "fire_if_within
+	// 15m"), otherwise skip the missed runs.
+	MisfireFireIfWithin MisfirePolicy = "fire_if_within"
+
+	defaultMisfirePolicy = MisfireFireOnce
+)
+
+// ParseMisfirePolicy - returns the misfire policy with the specified name. If
+// no name is specified, the default misfire policy is returned.
+func ParseMisfirePolicy(policy string) (MisfirePolicy, error) {
+	fields := strings.Fields(policy)
+	if len(fields) == 0 {
+		return defaultMisfirePolicy, nil
+	}
+
+	switch MisfirePolicy(fields[0]) {
+	case MisfireFireOnce, MisfireFireAll, MisfireSkip:
+		if len(fields) == 1 {
+			return MisfirePolicy(fields[0]), nil
+		}
+
+	case MisfireFireIfWithin:
+		if len(fields) != 2 {
+			break
+		}
+		window, err := time.ParseDuration(fields[1])
+		if err == nil && window > 0 {
+			return MisfirePolicy(strings.Join(fields, " ")), nil
+		}
+	}
+	return "", ErrInvalidMisfirePolicy
+}
+
+// ParseResumeMisfirePolicy - returns the misfire policy with the specified
+// name, to be applied when resuming a paused task. If no name is specified, an
+// empty policy is returned and the misfire policy of each task is applied.
+// Runs missed while a task was paused are never all run, so fire_all cannot be
+// specified, and tasks with the fire_all misfire policy are run once.
+func ParseResumeMisfirePolicy(policy string) (MisfirePolicy, error) {
+	if strings.TrimSpace(policy) == "" {
+		return "", nil
+	}
+
+	parsed, err := ParseMisfirePolicy(policy)
+	if err != nil || parsed == MisfireFireAll {
+		return "", ErrInvalidMisfirePolicy
+	}
+	return parsed, nil
+}
+
+// Return the name of the misfire policy, without any parameters.
+func (p MisfirePolicy) name() MisfirePolicy {
+	name, _, _ := strings.Cut(string(p), " ")
+	return MisfirePolicy(name)
+}
+
+// Return the window within which missed runs are run for the fire_if_within
+// policy.
+func (p MisfirePolicy) window() time.Duration {
+	_, value, _ := strings.Cut(string(p), " ")
+	window, _ := time.ParseDuration(value)
+	return window
+}
+
+// MisfirePolicy - specifies how runs of the task missed while the scheduler
+// was unable to dispatch them, or while the task was paused, are handled.
+func (s *ScheduledTask) MisfirePolicy(policy string) *ScheduledTask {
+	if policy == "" {
+		return s
+	}
+
+	parsed, err := ParseMisfirePolicy(policy)
+	if err != nil {
+		s.error = wrapOrError(s.error, err)
+		return s
+	}
+
+	s.TaskInfo.MisfirePolicy = string(parsed)
+	return s
+}
+
+// Return the misfire policy of the task. Tasks created without a misfire
+// policy use the default misfire policy.
+func taskMisfirePolicy(task *db.Task) MisfirePolicy {
+	policy, err := ParseMisfirePolicy(task.MisfirePolicy)
+	if err != nil {
+		return defaultMisfirePolicy
+	}
+	return policy
+}
+
+// Check if the scheduled run was missed. Runs are normally dispatched within
+// one execution quantum of the time at which they are due, so runs which are
+// due earlier than that were missed, for instance while the scheduler was down
+// or unable to read the scheduled runs.
+func isMissedRun(run *db.ScheduledRun, now time.Time) bool {
+	return now.Sub(run.NextRun.Add(-schedConfig.Lookahead)) >
+		getExecutionQuantum()
+}
+
+// Decide how to handle a missed run of the task scheduled at the specified
+// time, as per the misfire policy of the task. Returns the decision recorded
+// in the run history of the task.
+func misfireDecision(policy MisfirePolicy, runTime time.Time,
+	now time.Time) string {
+	switch policy.name() {
+	case MisfireSkip:
+		return db.MisfireDecisionSkipped
+
+	case MisfireFireAll:
+		return db.MisfireDecisionFired
+
+	case MisfireFireIfWithin:
+		if now.Sub(runTime) > policy.window() {
+			return db.MisfireDecisionSkipped
+		}
+	}
+	return db.MisfireDecisionFiredOnce
+}
+
+// Calculate the time of the next run of a paused task which is resumed at the
+// specified instant (now). If the run that was scheduled when the task was
+// paused has been missed, the next run is determined by the misfire policy.
+// Returns false if no run of the task needs to be scheduled.
+func resumeRunTime(task *db.Task, policy MisfirePolicy, now time.Time,
+	loc *time.Location) (time.Time, bool) {
+	if !hasPendingRun(task) {
+		return time.Time{}, false
+	}
+
+	if task.NextRun.After(now) {
+		return task.NextRun, true
+	}
+
+	if misfireDecision(policy, task.NextRun, now) == db.MisfireDecisionSkipped {
+		return nextRunAfter(task, task.NextRun, now, loc)
+	}
+	if !withinScheduleBounds(task, now, task.RunCount) {
+		return time.Time{}, false
+	}
+	return now, true
+}
+
+// Check if the task has a scheduled run which has not yet been dispatched. A
+// run is recorded as the current run of the task when it is dispatched.
+func hasPendingRun(task *db.Task) bool {
+	return !task.NextRun.IsZero() && !task.NextRun.Equal(task.CurrentRun)
+}
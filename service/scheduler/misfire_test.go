@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestParseMisfirePolicy(t *testing.T) {
+	for value, expected := range map[string]MisfirePolicy{
+		"":                   MisfireFireOnce,
+		"fire_once":          MisfireFireOnce,
+		"fire_all":           MisfireFireAll,
+		"skip":               MisfireSkip,
+		"fire_if_within 15m": "fire_if_within 15m",
+	} {
+		policy, err := ParseMisfirePolicy(value)
+		if err != nil || policy != expected {
+			t.Errorf("Unexpected policy %q for %q with error %v\n", policy,
+				value, err)
+		}
+	}
+
+	for _, value := range []string{"sometimes", "skip 15m", "fire_if_within",
+		"fire_if_within -5m", "fire_if_within soon"} {
+		_, err := ParseMisfirePolicy(value)
+		if err != ErrInvalidMisfirePolicy {
+			t.Errorf("%q: expected an invalid misfire policy error, got %v\n",
+				value, err)
+		}
+	}
+}
+
+func TestParseResumeMisfirePolicy(t *testing.T) {
+	policy, err := ParseResumeMisfirePolicy("")
+	if err != nil || policy != "" {
+		t.Errorf("Expected no policy for an empty value, got %q (%v)\n",
+			policy, err)
+	}
+
+	// Runs missed while a task was paused are never all run.
+	_, err = ParseResumeMisfirePolicy("fire_all")
+	if err != ErrInvalidMisfirePolicy {
+		t.Errorf("Expected an invalid misfire policy error, got %v\n", err)
+	}
+}
+
+func TestMisfireDecision(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+	missedRun := now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		policy   MisfirePolicy
+		expected string
+	}{
+		{MisfireFireOnce, db.MisfireDecisionFiredOnce},
+		{MisfireFireAll, db.MisfireDecisionFired},
+		{MisfireSkip, db.MisfireDecisionSkipped},
+		{"fire_if_within 15m", db.MisfireDecisionFiredOnce},
+		{"fire_if_within 5m", db.MisfireDecisionSkipped},
+	}
+
+	for _, test := range tests {
+		decision := misfireDecision(test.policy, missedRun, now)
+		if decision != test.expected {
+			t.Errorf("%q: expected decision %q, got %q\n", test.policy,
+				test.expected, decision)
+		}
+	}
+}
+
+func TestResumeRunTime(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+	missedRun := now.Add(-90 * time.Minute)
+	futureRun := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		task     *db.Task
+		policy   MisfirePolicy
+		expected time.Time
+		ok       bool
+	}{
+		{"FutureRun",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: futureRun},
+			MisfireSkip, futureRun, true},
+		{"MissedRunFireOnce",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: missedRun},
+			MisfireFireOnce, now, true},
+		{"MissedRunSkip",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: missedRun},
+			MisfireSkip, now.Add(time.Hour / 2), true},
+		{"MissedRunOutsideWindow",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: missedRun},
+			"fire_if_within 1h", now.Add(time.Hour / 2), true},
+		{"DispatchedRun",
+			&db.Task{Unit: common.Once, NextRun: missedRun,
+				CurrentRun: missedRun},
+			MisfireFireOnce, time.Time{}, false},
+		{"NoScheduledRun",
+			&db.Task{Unit: common.Once},
+			MisfireFireOnce, time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		nextRun, ok := resumeRunTime(test.task, test.policy, now, time.UTC)
+		if ok != test.ok || !nextRun.Equal(test.expected) {
+			t.Errorf("%s: expected %v (%v), got %v (%v)\n", test.name,
+				test.expected, test.ok, nextRun, ok)
+		}
+	}
+}
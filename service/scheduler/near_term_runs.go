@@ -0,0 +1,257 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// Maximum number of near-term runs dispatched concurrently.
+	nearTermDispatchConcurrency = 16
+)
+
+// Runs loaded by the scheduler daemon that are due before the start of its
+// next pass. These are held in memory and fired at their exact time, so that
+// tasks scheduled more often than once per execution quantum run on time.
+var nearTermRuns = newNearTermRunQueue(dispatchNearTermRun)
+
+// A run held in the near-term run queue, along with the time at which it is
+// fired.
+type nearTermRun struct {
+	run    *db.ScheduledRun
+	fireAt time.Time
+}
+
+// Min-heap of near-term runs ordered by the time at which they are fired.
+type nearTermRunHeap []*nearTermRun
+
+func (h nearTermRunHeap) Len() int           { return len(h) }
+func (h nearTermRunHeap) Less(i, j int) bool { return h[i].fireAt.Before(h[j].fireAt) }
+func (h nearTermRunHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *nearTermRunHeap) Push(x interface{}) {
+	*h = append(*h, x.(*nearTermRun))
+}
+
+func (h *nearTermRunHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// Identifies a scheduled run of a task. A retry of the task may be due at the
+// same time as its next regular run. The time of the run is kept at the
+// millisecond precision with which it is stored in the database, so that a run
+// queued when it is scheduled matches the same run read back from the database.
+type runKey struct {
+	taskID  gocql.UUID
+	nextRun int64
+	isRetry bool
+}
+
+func newRunKey(run *db.ScheduledRun) runKey {
+	return runKey{taskID: run.TaskID, nextRun: run.NextRun.UnixMilli(),
+		isRetry: run.IsRetry}
+}
+
+// Queue of the near-term runs, which fires each run at its exact time.
+type nearTermRunQueue struct {
+	mutex sync.Mutex
+	runs  nearTermRunHeap
+
+	// Runs which have been queued or fired, along with the later of the time
+	// of the run and the time it was queued. The scheduler daemon may load
+	// these runs again before they have been rescheduled in the database, so
+	// they are remembered until this time is older than the previous pass of
+	// the scheduler daemon. Missed runs are remembered from the time they are
+	// queued, as they are fired as soon as they are queued.
+	known map[runKey]time.Time
+
+	// Runs scheduled after the horizon are left for a later pass of the
+	// scheduler daemon.
+	horizon time.Time
+
+	// Runs are fired this far ahead of their scheduled time.
+	lookahead time.Duration
+
+	// Signalled when a run is queued ahead of the earliest queued run.
+	wakeup chan struct{}
+
+	// Limits the number of runs dispatched concurrently.
+	slots chan struct{}
+
+	// Dispatches a run. Returns false if the run could not be dispatched.
+	dispatch func(*db.ScheduledRun) bool
+}
+
+func newNearTermRunQueue(dispatch func(*db.ScheduledRun) bool) *nearTermRunQueue {
+	return &nearTermRunQueue{
+		known:    map[runKey]time.Time{},
+		wakeup:   make(chan struct{}, 1),
+		slots:    make(chan struct{}, nearTermDispatchConcurrency),
+		dispatch: dispatch,
+	}
+}
+
+// Set the time up to which runs are accepted by the queue, and forget the runs
+// scheduled before the specified time which are no longer queued.
+func (q *nearTermRunQueue) setHorizon(horizon time.Time,
+	lookahead time.Duration, forgetBefore time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.horizon = horizon
+	q.lookahead = lookahead
+	for key, runTime := range q.known {
+		if runTime.Before(forgetBefore) {
+			delete(q.known, key)
+		}
+	}
+}
+
+// Check if the run has already been queued or fired.
+func (q *nearTermRunQueue) isKnown(run *db.ScheduledRun) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	_, ok := q.known[newRunKey(run)]
+	return ok
+}
+
+// Queue the run to be fired at its scheduled time. Returns false if the run is
+// scheduled after the horizon of the queue, or has already been queued.
+func (q *nearTermRunQueue) add(run *db.ScheduledRun) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	key := newRunKey(run)
+	if run.NextRun.After(q.horizon) {
+		return false
+	}
+	if _, ok := q.known[key]; ok {
+		return false
+	}
+
+	item := &nearTermRun{run: run, fireAt: run.NextRun.Add(-q.lookahead)}
+	q.known[key] = run.NextRun
+	if now := time.Now(); now.After(run.NextRun) {
+		q.known[key] = now
+	}
+	heap.Push(&q.runs, item)
+	metrics.MetricNearTermRunsQueued.Set(float64(len(q.runs)))
+
+	// Wake up the queue if this run is now the earliest run.
+	if q.runs[0] == item {
+		select {
+		case q.wakeup <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// Forget the run, so that it is picked up again by the next pass of the
+// scheduler daemon.
+func (q *nearTermRunQueue) forget(run *db.ScheduledRun) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.known, newRunKey(run))
+}
+
+// Return the runs that are due to be fired and the time until the next run is
+// due. If no runs are queued, the time until the next run is negative.
+func (q *nearTermRunQueue) dueRuns(now time.Time) ([]*db.ScheduledRun,
+	time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var due []*db.ScheduledRun
+	for len(q.runs) != 0 && !q.runs[0].fireAt.After(now) {
+		due = append(due, heap.Pop(&q.runs).(*nearTermRun).run)
+	}
+	metrics.MetricNearTermRunsQueued.Set(float64(len(q.runs)))
+
+	if len(q.runs) == 0 {
+		return due, -1
+	}
+	return due, q.runs[0].fireAt.Sub(now)
+}
+
+// Fire the queued runs at their scheduled time until the context is cancelled.
+func (q *nearTermRunQueue) run(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		due, wait := q.dueRuns(time.Now())
+		for _, run := range due {
+			q.fire(ctx, run)
+		}
+
+		if wait < 0 {
+			wait = time.Hour
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-q.wakeup:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Dispatch the run, limiting the number of runs dispatched concurrently. Once
+// dispatched, the next run of a recurring task is queued if it is due before
+// the horizon of the queue.
+func (q *nearTermRunQueue) fire(ctx context.Context, run *db.ScheduledRun) {
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+
+	go func() {
+		defer func() { <-q.slots }()
+
+		firedRun := *run
+		if !q.dispatch(run) {
+			q.forget(&firedRun)
+			return
+		}
+		if run.NextRun.After(firedRun.NextRun) {
+			q.add(run)
+		}
+	}()
+}
+
+// Dispatch a near-term run if this scheduler instance still holds the
+// scheduler daemon lease.
+func dispatchNearTermRun(run *db.ScheduledRun) bool {
+	if schedCtx.Err() != nil || !holdsSchedulerLease() {
+		schedLogger.Info("Dropping a near-term run as the scheduler daemon lease is no longer held!",
+			zap.String("Task ID", run.TaskID.String()),
+			zap.Time("Next Run", run.NextRun),
+		)
+		return false
+	}
+	return dispatchScheduledRun(run)
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestNearTermRunQueue(t *testing.T) {
+	var (
+		mutex sync.Mutex
+		fired []time.Duration
+	)
+	start := time.Now()
+	period := 20 * time.Millisecond
+
+	// Recurring runs are rescheduled on dispatch, as by dispatchScheduledRun.
+	q := newNearTermRunQueue(func(run *db.ScheduledRun) bool {
+		mutex.Lock()
+		fired = append(fired, time.Since(run.NextRun))
+		mutex.Unlock()
+		run.NextRun = run.NextRun.Add(period)
+		return true
+	})
+	q.setHorizon(start.Add(5*period+period/2), 0, start)
+
+	run := &db.ScheduledRun{TaskID: gocql.TimeUUID(), NextRun: start.Add(period)}
+	if !q.add(run) {
+		t.Fatalf("Failed to queue a run within the horizon\n")
+	}
+	duplicate := *run
+	if q.add(&duplicate) || !q.isKnown(&duplicate) {
+		t.Errorf("Expected a queued run to be known and not queued again\n")
+	}
+	stored := &db.ScheduledRun{TaskID: run.TaskID,
+		NextRun: run.NextRun.Truncate(time.Millisecond)}
+	if q.add(stored) || !q.isKnown(stored) {
+		t.Errorf("Expected a queued run read back from the database to be known\n")
+	}
+	retry := &db.ScheduledRun{TaskID: run.TaskID, NextRun: run.NextRun,
+		IsRetry: true}
+	if q.isKnown(retry) {
+		t.Errorf("Expected a retry due with a queued run not to be known\n")
+	}
+	if q.add(&db.ScheduledRun{TaskID: gocql.TimeUUID(),
+		NextRun: start.Add(time.Hour)}) {
+		t.Errorf("Expected a run after the horizon not to be queued\n")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go q.run(ctx)
+	time.Sleep(8 * period)
+	cancel()
+
+	// The run recurs until its next run is beyond the horizon.
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(fired) != 5 {
+		t.Fatalf("Expected 5 runs to be fired, got %d\n", len(fired))
+	}
+	for i, lateness := range fired {
+		if lateness < 0 || lateness > 2*period {
+			t.Errorf("Run %d fired %v after it was due\n", i, lateness)
+		}
+	}
+
+	// Fired runs are forgotten once they are older than the horizon.
+	q.setHorizon(time.Now().Add(time.Hour), 0, time.Now())
+	if q.isKnown(&duplicate) {
+		t.Errorf("Expected the fired run to be forgotten\n")
+	}
+}
+
+func TestNearTermRunQueue_FailedDispatch(t *testing.T) {
+	dispatched := make(chan struct{}, 1)
+	q := newNearTermRunQueue(func(run *db.ScheduledRun) bool {
+		dispatched <- struct{}{}
+		return false
+	})
+	q.setHorizon(time.Now().Add(time.Hour), 0, time.Now())
+
+	run := &db.ScheduledRun{TaskID: gocql.TimeUUID(), NextRun: time.Now()}
+	q.add(run)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.run(ctx)
+
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		t.Fatalf("The run was not fired\n")
+	}
+
+	// Runs which could not be dispatched are left for the scheduler daemon.
+	deadline := time.Now().Add(time.Second)
+	for q.isKnown(run) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if q.isKnown(run) {
+		t.Errorf("Expected a run that failed to dispatch to be forgotten\n")
+	}
+}
@@ -0,0 +1,408 @@
+package scheduler
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	daysPerWeek = 7
+)
+
+// Calculate the time at which a newly scheduled task should run for the first
+// time. Crontab and recurrence rule tasks, and tasks scheduled on specific
+// times of day, weekdays or days of the month run at the first such occurrence
+// at or after the requested start time. All other tasks run at the start time.
+func firstRunTime(task *db.Task, now time.Time, loc *time.Location) time.Time {
+	start := now
+	if !task.StartAt.IsZero() && task.StartAt.After(now) {
+		start = task.StartAt
+	}
+
+	switch task.Unit {
+	case common.Crontab, common.RRule:
+		nextRun, ok := nextRunTime(task, start.Add(-time.Nanosecond), loc)
+		if ok {
+			return nextRun
+		}
+
+	case common.Days, common.Weeks, common.Months:
+		if len(task.RunAt) == 0 && isScheduledDay(task, start.In(loc)) {
+			return start
+		}
+		nextRun, ok := nextRunTime(task, start.Add(-time.Nanosecond), loc)
+		if ok {
+			return nextRun
+		}
+	}
+	return start
+}
+
+// Check if the task is scheduled to run on the day of the specified instant.
+func isScheduledDay(task *db.Task, day time.Time) bool {
+	switch task.Unit {
+	case common.Weeks:
+		return len(task.ScheduledWeekdays) == 0 ||
+			in(task.ScheduledWeekdays, day.Weekday())
+
+	case common.Months:
+		for _, scheduledDay := range daysOfMonth(task, day, day.Day()) {
+			if scheduledDay == day.Day() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Calculate the time of the first run of the task that is scheduled after the
+// specified instant (now), given the time at which the task was last scheduled
+// to run. Runs that would have occurred between the last scheduled run and now
+// are skipped. Returns false if the task does not recur, or if the next run
+// would be outside the bounds of the schedule of the task.
+func nextRunAfter(task *db.Task, lastScheduled time.Time, now time.Time,
+	loc *time.Location) (time.Time, bool) {
+	nextRun, ok := nextRecurrenceAfter(task, lastScheduled, now, loc)
+	if !ok || !withinScheduleBounds(task, nextRun, task.RunCount) {
+		return time.Time{}, false
+	}
+	return nextRun, true
+}
+
+// Check if a run of the task at the specified time is within the bounds of its
+// schedule, given the number of runs of the task dispatched before it.
+func withinScheduleBounds(task *db.Task, runTime time.Time, runCount int) bool {
+	if !task.EndAt.IsZero() && runTime.After(task.EndAt) {
+		return false
+	}
+	return task.MaxRuns <= 0 || runCount < task.MaxRuns
+}
+
+// Calculate the time of the first recurrence of the task after the specified
+// instant, as for nextRunAfter, irrespective of the bounds of its schedule.
+// Runs of tasks with a jitter window are offset from the times in their
+// schedule, so the offset is removed before calculating the next scheduled
+// time and applied again to the result.
+func nextRecurrenceAfter(task *db.Task, lastScheduled time.Time, now time.Time,
+	loc *time.Location) (time.Time, bool) {
+	offset := jitterOffset(task)
+	nextRun, ok := nextScheduledTimeAfter(task, lastScheduled.Add(-offset),
+		now.Add(-offset), loc)
+	if !ok {
+		return time.Time{}, false
+	}
+	return nextRun.Add(offset), true
+}
+
+// Calculate the first time in the schedule of the task after the specified
+// instant, given the time in the schedule of the last run of the task.
+func nextScheduledTimeAfter(task *db.Task, lastScheduled time.Time,
+	now time.Time, loc *time.Location) (time.Time, bool) {
+
+	// Fixed period schedules can skip ahead directly without having to walk
+	// through every missed occurrence.
+	period, ok := fixedPeriod(task)
+	if ok {
+		nextRun := lastScheduled.Add(period)
+		if !nextRun.After(now) {
+			missed := now.Sub(nextRun)/period + 1
+			nextRun = nextRun.Add(missed * period)
+		}
+		return nextRun, true
+	}
+
+	nextRun, ok := nextRunTime(task, lastScheduled, loc)
+	for ok && !nextRun.After(now) {
+		prevRun := nextRun
+		nextRun, ok = nextRunTime(task, prevRun, loc)
+		if ok && !nextRun.After(prevRun) {
+			return time.Time{}, false
+		}
+	}
+	return nextRun, ok
+}
+
+// Calculate the time of the run of the task that follows the run scheduled at
+// the specified time. Returns false if the task does not recur.
+func nextRunTime(task *db.Task, after time.Time,
+	loc *time.Location) (time.Time, bool) {
+
+	period, ok := fixedPeriod(task)
+	if ok {
+		return after.Add(period), true
+	}
+
+	switch task.Unit {
+	case common.Days:
+		return nextDailyRun(task, after.In(loc)), true
+
+	case common.Weeks:
+		return nextWeeklyRun(task, after.In(loc)), true
+
+	case common.Months:
+		return nextMonthlyRun(task, after.In(loc)), true
+
+	case common.Crontab:
+		return nextCronRun(task, after)
+
+	case common.RRule:
+		return nextRRuleRun(task, after, loc)
+	}
+
+	return time.Time{}, false
+}
+
+// Rebuild the cron schedule stored with the task and return the time of the
+// run that follows the specified instant.
+func nextCronRun(task *db.Task, after time.Time) (time.Time, bool) {
+	if task.CronSpec == "" {
+		return time.Time{}, false
+	}
+
+	cronSchedule, err := parseCronSpec(task.CronSpec, task.CronWithSeconds)
+	if err != nil {
+		schedLogger.Error("Failed to parse the cron expression stored for the task!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.String("Cron expression", task.CronSpec),
+			zap.Error(err),
+		)
+		return time.Time{}, false
+	}
+
+	spec, ok := cronSchedule.(*cron.SpecSchedule)
+	if !ok {
+		// Descriptors such as "@every 1h" run at fixed intervals of elapsed
+		// time, regardless of the wall clock.
+		nextRun := cronSchedule.Next(after)
+		return nextRun, !nextRun.IsZero()
+	}
+	return nextWallClockCronRun(spec, after)
+}
+
+// Return the time of the run of the cron schedule that follows the specified
+// instant. The fields of the cron schedule are matched against the wall clock
+// in the location of the schedule, and the matching times of day are resolved
+// in the same way as the times of day of other tasks. Runs at times of day
+// skipped when the clocks move forward are therefore moved past the gap rather
+// than missed, and runs at times of day which occur twice when the clocks move
+// back are not repeated.
+func nextWallClockCronRun(spec *cron.SpecSchedule,
+	after time.Time) (time.Time, bool) {
+	loc := spec.Location
+	wallSpec := *spec
+	wallSpec.Location = time.UTC
+
+	local := after.In(loc)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(),
+		local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	for {
+		wall = wallSpec.Next(wall)
+		if wall.IsZero() {
+			return time.Time{}, false
+		}
+
+		// The second occurrence of a repeated time of day resolves to the
+		// first occurrence, which may not follow the specified instant.
+		nextRun := wallClockTime(wall.Year(), wall.Month(), wall.Day(),
+			wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+		if nextRun.After(after) {
+			return nextRun, true
+		}
+	}
+}
+
+// Rebuild the recurrence set stored with the task and return the time of the
+// occurrence that follows the specified instant.
+func nextRRuleRun(task *db.Task, after time.Time,
+	loc *time.Location) (time.Time, bool) {
+	if task.RRuleSpec == "" {
+		return time.Time{}, false
+	}
+
+	rrule, err := parseRecurrenceSet(strings.Fields(task.RRuleSpec), loc,
+		time.Time{})
+	if err != nil {
+		schedLogger.Error("Failed to parse the recurrence rule stored for the task!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.String("Recurrence rule", task.RRuleSpec),
+			zap.Error(err),
+		)
+		return time.Time{}, false
+	}
+
+	return rrule.next(after)
+}
+
+// Return the interval between runs for tasks scheduled at a fixed period, such
+// as every 30 minutes or every 2 hours.
+func fixedPeriod(task *db.Task) (time.Duration, bool) {
+	var unit time.Duration
+
+	switch task.Unit {
+	case common.Duration:
+		if task.Duration <= 0 {
+			return 0, false
+		}
+		return task.Duration, true
+	case common.Milliseconds:
+		unit = time.Millisecond
+	case common.Seconds:
+		unit = time.Second
+	case common.Minutes:
+		unit = time.Minute
+	case common.Hours:
+		unit = time.Hour
+	default:
+		return 0, false
+	}
+
+	if task.Interval <= 0 {
+		return 0, false
+	}
+	return time.Duration(task.Interval) * unit, true
+}
+
+// Return the number of days, weeks or months between runs of a task.
+func calendarInterval(task *db.Task) int {
+	if task.Interval <= 0 {
+		return 1
+	}
+	return task.Interval
+}
+
+// Return the times of day at which the task runs. If no time of day was
+// requested for the task, it runs at the same time of day as the anchor run.
+func timesOfDay(task *db.Task, anchor time.Time) []time.Duration {
+	if len(task.RunAt) != 0 {
+		runAt := append([]time.Duration(nil), task.RunAt...)
+		sort.Slice(runAt, func(i, j int) bool { return runAt[i] < runAt[j] })
+		return runAt
+	}
+
+	if !task.StartAt.IsZero() {
+		anchor = task.StartAt.In(anchor.Location())
+	}
+	return []time.Duration{time.Duration(anchor.Hour())*time.Hour +
+		time.Duration(anchor.Minute())*time.Minute +
+		time.Duration(anchor.Second())*time.Second}
+}
+
+// Return the specified time of day on the day of the specified instant, in
+// the location of the instant.
+func atTimeOfDay(day time.Time, offset time.Duration) time.Time {
+	return wallClockTime(day.Year(), day.Month(), day.Day(),
+		int(offset/time.Hour), int(offset%time.Hour/time.Minute),
+		int(offset%time.Minute/time.Second), int(offset%time.Second),
+		day.Location())
+}
+
+// Return the first of the specified times of day on the specified day that is
+// after the specified instant.
+func firstTimeOfDayAfter(day time.Time, runAt []time.Duration,
+	after time.Time) (time.Time, bool) {
+	for _, offset := range runAt {
+		candidate := atTimeOfDay(day, offset)
+		if candidate.After(after) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func nextDailyRun(task *db.Task, after time.Time) time.Time {
+	runAt := timesOfDay(task, after)
+
+	// Check if there is another run scheduled later in the same day.
+	nextRun, ok := firstTimeOfDayAfter(after, runAt, after)
+	if ok && len(task.RunAt) != 0 {
+		return nextRun
+	}
+
+	return atTimeOfDay(after.AddDate(0, 0, calendarInterval(task)), runAt[0])
+}
+
+func nextWeeklyRun(task *db.Task, after time.Time) time.Time {
+	runAt := timesOfDay(task, after)
+	weekdays := task.ScheduledWeekdays
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{after.Weekday()}
+	}
+
+	// Check the remaining days of the current week for a scheduled run.
+	for day := after; day.Weekday() >= after.Weekday(); day = day.AddDate(0, 0, 1) {
+		if in(weekdays, day.Weekday()) {
+			nextRun, ok := firstTimeOfDayAfter(day, runAt, after)
+			if ok {
+				return nextRun
+			}
+		}
+		if day.Weekday() == time.Saturday {
+			break
+		}
+	}
+
+	// Move on to the first scheduled weekday in the next scheduled week.
+	weekStart := after.AddDate(0, 0, -int(after.Weekday())+
+		daysPerWeek*calendarInterval(task))
+	for offset := 0; offset < daysPerWeek; offset++ {
+		day := weekStart.AddDate(0, 0, offset)
+		if in(weekdays, day.Weekday()) {
+			return atTimeOfDay(day, runAt[0])
+		}
+	}
+	return atTimeOfDay(weekStart, runAt[0])
+}
+
+func nextMonthlyRun(task *db.Task, after time.Time) time.Time {
+	runAt := timesOfDay(task, after)
+
+	// Check the remaining days of the current month for a scheduled run.
+	for _, day := range daysOfMonth(task, after, after.Day()) {
+		if day < after.Day() {
+			continue
+		}
+		nextRun, ok := firstTimeOfDayAfter(time.Date(after.Year(),
+			after.Month(), day, 0, 0, 0, 0, after.Location()), runAt, after)
+		if ok {
+			return nextRun
+		}
+	}
+
+	// Move on to the first scheduled day in the next scheduled month.
+	month := time.Date(after.Year(), after.Month()+
+		time.Month(calendarInterval(task)), 1, 0, 0, 0, 0, after.Location())
+	return atTimeOfDay(month.AddDate(0, 0,
+		daysOfMonth(task, month, after.Day())[0]-1), runAt[0])
+}
+
+// Return the sorted days of the month on which the task runs in the month of
+// the specified instant. The special value -1 denotes the last day of the
+// month. If no days of the month were requested for the task, it runs on the
+// same day of the month as the anchor run.
+func daysOfMonth(task *db.Task, month time.Time, anchorDay int) []int {
+	if len(task.ScheduledDaysOfTheMonth) == 0 {
+		return []int{min(anchorDay, lastDayOfMonth(month))}
+	}
+
+	days := make([]int, 0, len(task.ScheduledDaysOfTheMonth))
+	for _, day := range task.ScheduledDaysOfTheMonth {
+		if day == -1 {
+			day = lastDayOfMonth(month)
+		}
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days
+}
+
+func lastDayOfMonth(month time.Time) int {
+	return time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0,
+		month.Location()).Day()
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestNextRunAfter_FixedPeriod(t *testing.T) {
+	task := &db.Task{Unit: common.Hours, Interval: 2}
+	last := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	nextRun, ok := nextRunAfter(task, last, last.Add(time.Minute), time.UTC)
+	if !ok || !nextRun.Equal(last.Add(2*time.Hour)) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+
+	// Runs missed while the scheduler was down are skipped.
+	nextRun, ok = nextRunAfter(task, last, last.Add(5*time.Hour), time.UTC)
+	if !ok || !nextRun.Equal(last.Add(6*time.Hour)) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+}
+
+func TestNextRunAfter_Weekly(t *testing.T) {
+	// Every week on Monday and Thursday at 09:00.
+	task := &db.Task{
+		Unit:              common.Weeks,
+		Interval:          1,
+		RunAt:             []time.Duration{9 * time.Hour},
+		ScheduledWeekdays: []time.Weekday{time.Monday, time.Thursday},
+	}
+	monday := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC)
+
+	nextRun, ok := nextRunAfter(task, monday, monday, time.UTC)
+	if !ok || !nextRun.Equal(monday.AddDate(0, 0, 3)) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+
+	nextRun, ok = nextRunAfter(task, nextRun, nextRun, time.UTC)
+	if !ok || !nextRun.Equal(monday.AddDate(0, 0, 7)) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+}
+
+func TestNextRunAfter_Monthly(t *testing.T) {
+	// On the 15th and the last day of every month at 12:30.
+	task := &db.Task{
+		Unit:                    common.Months,
+		Interval:                1,
+		RunAt:                   []time.Duration{12*time.Hour + 30*time.Minute},
+		ScheduledDaysOfTheMonth: []int{15, -1},
+	}
+	last := time.Date(2024, time.February, 15, 12, 30, 0, 0, time.UTC)
+
+	nextRun, ok := nextRunAfter(task, last, last, time.UTC)
+	expected := time.Date(2024, time.February, 29, 12, 30, 0, 0, time.UTC)
+	if !ok || !nextRun.Equal(expected) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+
+	nextRun, ok = nextRunAfter(task, nextRun, nextRun, time.UTC)
+	expected = time.Date(2024, time.March, 15, 12, 30, 0, 0, time.UTC)
+	if !ok || !nextRun.Equal(expected) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+}
+
+func TestNextRunAfter_Once(t *testing.T) {
+	task := &db.Task{Unit: common.Once}
+	now := time.Now()
+
+	_, ok := nextRunAfter(task, now, now, time.UTC)
+	if ok {
+		t.Errorf("One time tasks must not be rescheduled\n")
+	}
+}
+
+func TestFirstRunTime(t *testing.T) {
+	// Every day at 09:00, requested on a day after 09:00.
+	task := &db.Task{
+		Unit:     common.Days,
+		Interval: 1,
+		RunAt:    []time.Duration{9 * time.Hour},
+	}
+	now := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+	firstRun := firstRunTime(task, now, time.UTC)
+	expected := time.Date(2024, time.March, 5, 9, 0, 0, 0, time.UTC)
+	if !firstRun.Equal(expected) {
+		t.Errorf("Unexpected first run %v\n", firstRun)
+	}
+}
+
+func TestNextRunAfter_Crontab(t *testing.T) {
+	// At 30 minutes past every hour, in the New York timezone.
+	task := &db.Task{
+		Unit:     common.Crontab,
+		CronSpec: "CRON_TZ=America/New_York 30 * * * *",
+	}
+	last := time.Date(2024, time.March, 4, 10, 30, 0, 0, time.UTC)
+
+	nextRun, ok := nextRunAfter(task, last, last, time.UTC)
+	if !ok || !nextRun.Equal(last.Add(time.Hour)) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+}
+
+func TestNextRunAfter_Bounds(t *testing.T) {
+	last := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	// The task no longer runs after the end of the schedule.
+	task := &db.Task{Unit: common.Hours, Interval: 2,
+		EndAt: last.Add(3 * time.Hour)}
+	nextRun, ok := nextRunAfter(task, last, last, time.UTC)
+	if !ok || !nextRun.Equal(last.Add(2*time.Hour)) {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+	_, ok = nextRunAfter(task, nextRun, nextRun, time.UTC)
+	if ok {
+		t.Errorf("Expected no run after the end of the schedule\n")
+	}
+
+	// The task no longer runs once it has run the maximum number of times.
+	task = &db.Task{Unit: common.Hours, Interval: 2, MaxRuns: 3, RunCount: 2}
+	_, ok = nextRunAfter(task, last, last, time.UTC)
+	if !ok {
+		t.Errorf("Expected a run before the maximum number of runs\n")
+	}
+	task.RunCount = 3
+	_, ok = nextRunAfter(task, last, last, time.UTC)
+	if ok {
+		t.Errorf("Expected no run after the maximum number of runs\n")
+	}
+}
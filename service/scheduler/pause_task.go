@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"go.uber.org/zap"
+)
+
+var (
+	PauseTaskHandlerFunc         = pauseTask
+	ResumeTaskHandlerFunc        = resumeTask
+	PauseConsignmentHandlerFunc  = pauseConsignment
+	ResumeConsignmentHandlerFunc = resumeConsignment
+)
+
+// ConsignmentStatusChange - summary of the tasks processed when pausing or
+// resuming a consignment.
+type ConsignmentStatusChange struct {
+	ConsignmentID string `json:"consignment_id"`
+	TenantID      string `json:"tenant_id"`
+	UpdatedCount  int    `json:"updated_count"`
+	SkippedCount  int    `json:"skipped_count"`
+	ErrorCount    int    `json:"error_count"`
+}
+
+// Pause the specified task. The task is not dispatched to the device until it
+// is resumed. Pausing a task which is already paused has no effect.
+func pauseTask(taskID string, deviceID string) (*db.Task, error) {
+	task, err := db.GetTaskByID(taskID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pauseScheduledTask(task)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Pause the task, unless it has finished or is already paused. If the status
+// of the task changes while it is being paused, the task is read again and
+// pausing it is retried.
+func pauseScheduledTask(task *db.Task) error {
+	return changeTask(task, func(task *db.Task) error {
+		if isFinished(task) {
+			return ErrTaskFinished
+		}
+
+		if task.Status == db.TaskStatusPaused.String() {
+			return nil
+		}
+
+		err := db.PauseTask(task)
+		if err == db.ErrTaskPaused {
+			// Paused concurrently, read the task again to return it as paused.
+			return db.ErrTaskStatusChanged
+		}
+		if err != nil {
+			return err
+		}
+
+		schedLogger.Info("Paused the task!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.String("Device ID", task.DeviceID.String()),
+		)
+		return nil
+	})
+}
+
+// Resume the specified paused task. The next run of the task is scheduled as
+// per the specified misfire policy.
+func resumeTask(taskID string, deviceID string,
+	policy MisfirePolicy) (*db.Task, error) {
+	task, err := db.GetTaskByID(taskID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resumePausedTask(task, policy)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Resume the task and schedule its next run as per the specified misfire
+// policy. If no misfire policy is specified, the misfire policy of the task is
+// applied. If the device reports the status of the task while it is being
+// resumed, the task is read again and resuming it is retried.
+func resumePausedTask(task *db.Task, policy MisfirePolicy) error {
+	var nextRun time.Time
+	err := changeTask(task, func(task *db.Task) error {
+		if task.Status != db.TaskStatusPaused.String() {
+			return db.ErrTaskNotPaused
+		}
+
+		runPolicy := policy
+		if runPolicy == "" {
+			runPolicy = taskMisfirePolicy(task)
+		}
+
+		now := time.Now()
+		nextRun, _ = resumeRunTime(task, runPolicy, now, taskLocation(task))
+
+		// Pending retries are dropped when the task is paused, so the retry
+		// is scheduled again, unless a run of the task is due now anyway.
+		var retryRun time.Time
+		if task.PausedStatus == db.TaskStatusPendingRetry.String() &&
+			(nextRun.IsZero() || nextRun.After(now)) {
+			retryRun = now
+		}
+		return db.ResumeTask(task, nextRun, retryRun)
+	})
+	if err != nil {
+		return err
+	}
+
+	schedLogger.Info("Resumed the task!",
+		zap.String("Task ID", task.TaskID.String()),
+		zap.String("Device ID", task.DeviceID.String()),
+		zap.Time("Next Run", nextRun),
+	)
+	return nil
+}
+
+// Pause every task in the specified consignment that has not yet finished.
+func pauseConsignment(tenantID string,
+	consignmentID string) (*ConsignmentStatusChange, error) {
+	summary := &ConsignmentStatusChange{
+		ConsignmentID: consignmentID,
+		TenantID:      tenantID,
+	}
+
+	// No further stages of a rollout of the consignment start while it is
+	// paused.
+	pauseRollout(tenantID, consignmentID)
+
+	errorCount, err := forEachConsignmentTask(tenantID, consignmentID,
+		func(task *db.Task) {
+			if isFinished(task) ||
+				task.Status == db.TaskStatusPaused.String() {
+				summary.SkippedCount++
+				return
+			}
+
+			err := pauseScheduledTask(task)
+			if err == ErrTaskFinished {
+				summary.SkippedCount++
+				return
+			}
+			if err != nil {
+				summary.ErrorCount++
+				return
+			}
+			summary.UpdatedCount++
+		})
+	if err != nil {
+		return nil, err
+	}
+	summary.ErrorCount += errorCount
+
+	schedLogger.Info("Paused the consignment!",
+		zap.String("Consignment ID", consignmentID),
+		zap.String("Tenant ID", tenantID),
+		zap.Int("Paused", summary.UpdatedCount),
+		zap.Int("Skipped", summary.SkippedCount),
+		zap.Int("Failures", summary.ErrorCount),
+	)
+	return summary, nil
+}
+
+// Resume every paused task in the specified consignment. The next run of each
+// task is scheduled as per the specified misfire policy.
+func resumeConsignment(tenantID string, consignmentID string,
+	policy MisfirePolicy) (*ConsignmentStatusChange, error) {
+	summary := &ConsignmentStatusChange{
+		ConsignmentID: consignmentID,
+		TenantID:      tenantID,
+	}
+
+	errorCount, err := forEachConsignmentTask(tenantID, consignmentID,
+		func(task *db.Task) {
+			if task.Status != db.TaskStatusPaused.String() {
+				summary.SkippedCount++
+				return
+			}
+
+			err := resumePausedTask(task, policy)
+			if err != nil {
+				summary.ErrorCount++
+				return
+			}
+			summary.UpdatedCount++
+		})
+	if err != nil {
+		return nil, err
+	}
+	summary.ErrorCount += errorCount
+
+	resumeRollout(tenantID, consignmentID)
+
+	schedLogger.Info("Resumed the consignment!",
+		zap.String("Consignment ID", consignmentID),
+		zap.String("Tenant ID", tenantID),
+		zap.Int("Resumed", summary.UpdatedCount),
+		zap.Int("Skipped", summary.SkippedCount),
+		zap.Int("Failures", summary.ErrorCount),
+	)
+	return summary, nil
+}
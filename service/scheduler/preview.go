@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+const (
+	// Number of runs returned by a schedule preview if none is requested.
+	defaultPreviewRunCount = 10
+
+	// Maximum number of runs returned by a schedule preview.
+	maxPreviewRunCount = 100
+)
+
+var PreviewScheduleHandlerFunc = previewSchedule
+
+// SchedulePreview - the times at which a task with the specified schedule
+// would run, if it were scheduled now. If the schedule has a jitter window,
+// the run for each device is spread within the window following each time.
+type SchedulePreview struct {
+	Schedule     string      `json:"schedule"`
+	Timezone     string      `json:"timezone"`
+	Recurring    bool        `json:"recurring"`
+	JitterWindow string      `json:"jitter_window,omitempty"`
+	Runs         []time.Time `json:"runs"`
+}
+
+// Parse the specified schedule in the specified timezone and calculate the
+// times of the first runs of a task with that schedule, up to the specified
+// number of runs. Nothing is stored in the scheduler database.
+func previewSchedule(schedule string, timezone string,
+	count int) (*SchedulePreview, error) {
+	if count == 0 {
+		count = defaultPreviewRunCount
+	}
+	if count < 0 || count > maxPreviewRunCount {
+		return nil, ErrInvalidPreviewRunCount
+	}
+
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := parseSchedule(schedule, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	runTime := firstRunTime(task, time.Now(), loc)
+	preview := &SchedulePreview{
+		Schedule: schedule,
+		Timezone: loc.String(),
+		Runs:     []time.Time{runTime.In(loc)},
+	}
+	if task.JitterWindow > 0 {
+		preview.JitterWindow = task.JitterWindow.String()
+	}
+
+	for len(preview.Runs) < count {
+		nextRun, ok := nextRunTime(task, runTime, loc)
+		if !ok || !nextRun.After(runTime) ||
+			!withinScheduleBounds(task, nextRun, len(preview.Runs)) {
+			break
+		}
+		preview.Recurring = true
+		preview.Runs = append(preview.Runs, nextRun.In(loc))
+		runTime = nextRun
+	}
+
+	return preview, nil
+}
+
+// Parse and validate the specified schedule in the specified location, without
+// creating a task. Returns a task with the parsed schedule.
+func parseSchedule(schedule string, loc *time.Location) (*db.Task, error) {
+	s := &ScheduledTask{location: loc, TaskInfo: &db.Task{}}
+	err := s.ParseSchedule(schedule).validate()
+	if err != nil {
+		return nil, err
+	}
+	return s.TaskInfo, nil
+}
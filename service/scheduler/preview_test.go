@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPreviewSchedule(t *testing.T) {
+	preview, err := previewSchedule("every 2 hours", "America/New_York", 3)
+	if err != nil {
+		t.Errorf("Failed to preview the schedule with error %v\n", err)
+		return
+	}
+
+	if !preview.Recurring || len(preview.Runs) != 3 {
+		t.Errorf("Unexpected schedule preview: %+v\n", preview)
+		return
+	}
+	for i := 1; i < len(preview.Runs); i++ {
+		if preview.Runs[i].Sub(preview.Runs[i-1]) != 2*time.Hour {
+			t.Errorf("Unexpected interval between runs: %v\n", preview.Runs)
+		}
+	}
+	if preview.Runs[0].Location().String() != "America/New_York" {
+		t.Errorf("Runs are not in the requested timezone: %v\n",
+			preview.Runs[0].Location())
+	}
+}
+
+func TestPreviewScheduleErrors(t *testing.T) {
+	tests := []struct {
+		schedule string
+		token    string
+		position int
+		err      error
+	}{
+		{"every 2 fortnights", "fortnights", 8, ErrInvalidSchedulingUnit},
+		{"sometimes 2 hours", "sometimes", 0, ErrInvalidScheduleType},
+		{"every", "", 5, ErrMissingSchedulingUnit},
+		{"at 25:00", "25:00", 3, ErrUnsupportedTimeFormat},
+		{"every 1 monthdays 1,31", "1,31", 18, ErrInvalidDayOfMonthEntry},
+	}
+
+	for _, test := range tests {
+		_, err := previewSchedule(test.schedule, "", 0)
+
+		var scheduleErr *ScheduleError
+		if !errors.As(err, &scheduleErr) {
+			t.Errorf("%q: expected a schedule error, got %v\n",
+				test.schedule, err)
+			continue
+		}
+		if scheduleErr.Token != test.token ||
+			scheduleErr.Position != test.position ||
+			!errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected schedule error %+v\n", test.schedule,
+				scheduleErr)
+		}
+	}
+
+	_, err := previewSchedule("every 2 hours", "Mars/Olympus_Mons", 0)
+	if err != ErrInvalidTimezone {
+		t.Errorf("Expected an invalid timezone error, got %v\n", err)
+	}
+
+	_, err = previewSchedule("every 2 hours", "", maxPreviewRunCount+1)
+	if err != ErrInvalidPreviewRunCount {
+		t.Errorf("Expected an invalid run count error, got %v\n", err)
+	}
+}
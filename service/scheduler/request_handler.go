@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -81,6 +82,82 @@ func handleSchedulerInputQueueRequest(request *pb.CreateScheduledTaskRequest,
 		return nil, ErrInvalidRequest
 	}
 
+	// The scheduler reserves some message types for its own use.
+	if request.MessageType == common.CancelTaskMessageType {
+		schedLogger.Error("Reserved message type specified in the request!",
+			zap.String("Consignment ID: ", request.ConsignmentId),
+			zap.String("Message type: ", request.MessageType),
+		)
+		return nil, ErrInvalidRequest
+	}
+
+	// The schedule is interpreted in the requested timezone.
+	loc, err := loadLocation(request.Timezone)
+	if err != nil {
+		schedLogger.Error("Invalid timezone specified in the request!",
+			zap.String("Consignment ID: ", request.ConsignmentId),
+			zap.String("Timezone: ", request.Timezone),
+		)
+		return nil, err
+	}
+
+	// Reject requests with an invalid schedule up front, so that the reason
+	// is reported to the caller rather than as a failure for every device.
+	_, err = parseSchedule(request.Schedule, loc)
+	var scheduleErr *ScheduleError
+	if errors.As(err, &scheduleErr) {
+		return nil, scheduleErr
+	}
+
+	_, err = ParseMisfirePolicy(request.MisfirePolicy)
+	if err != nil {
+		schedLogger.Error("Invalid misfire policy specified in the request!",
+			zap.String("Consignment ID: ", request.ConsignmentId),
+			zap.String("Misfire policy: ", request.MisfirePolicy),
+		)
+		return nil, err
+	}
+
+	_, err = parseEndAt(request.EndAt)
+	if err != nil || request.MaxRuns < 0 {
+		schedLogger.Error("Invalid schedule bounds specified in the request!",
+			zap.String("Consignment ID: ", request.ConsignmentId),
+			zap.String("End at: ", request.EndAt),
+			zap.Int32("Max runs: ", request.MaxRuns),
+		)
+		if err == nil {
+			err = ErrInvalidMaxRuns
+		}
+		return nil, err
+	}
+
+	_, err = parseJitter(request.Jitter)
+	if err != nil {
+		schedLogger.Error("Invalid jitter specified in the request!",
+			zap.String("Consignment ID: ", request.ConsignmentId),
+			zap.String("Jitter: ", request.Jitter),
+		)
+		return nil, err
+	}
+
+	// Tasks for the devices in the later stages of a rollout are scheduled as
+	// the rollout advances.
+	deviceIDs := request.DeviceIds
+	var (
+		rollout *db.Rollout
+		pending int
+	)
+	if request.Rollout != nil {
+		rollout, deviceIDs, pending, err = startRollout(request)
+		if err != nil {
+			schedLogger.Error("Failed to start the rollout of the consignment!",
+				zap.String("Consignment ID: ", request.ConsignmentId),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+	}
+
 	response := &pb.CreateScheduledTaskResponse{
 		Version:        request.Version,
 		TaskCount:      0,
@@ -88,9 +165,11 @@ func handleSchedulerInputQueueRequest(request *pb.CreateScheduledTaskRequest,
 		ConsignmentId:  request.ConsignmentId,
 		TenantId:       request.TenantId,
 		TasksScheduled: []*pb.TaskInfo{},
+
+		PendingRolloutCount: uint32(pending),
 	}
 
-	for index, deviceID := range request.DeviceIds {
+	for index, deviceID := range deviceIDs {
 		if (index != 0) && (deviceID == common.BroadcastDeviceID) {
 			// Broadcast tasks must not specify any other device IDs
 			// in the request. Ignore any broadcast task request that
@@ -100,43 +179,18 @@ func handleSchedulerInputQueueRequest(request *pb.CreateScheduledTaskRequest,
 			continue
 		}
 
-		// Parse the provided task schedule and schedule the task.
-		newTask, err := NewScheduledTask(time.UTC, deviceID, request).
-			ParseSchedule(request.Schedule).
-			Schedule()
-		if err != nil {
-			switch err {
-			case db.ErrInvalidRequest, ErrInvalidSchedulingUnit,
-				ErrInvalidScheduleType:
-				metrics.MetricCreateTaskBadRequests.Inc()
-
-			default:
-				metrics.MetricCreateTaskInternalErrors.Inc()
-			}
+		scheduleDeviceTask(request, loc, deviceID, source, response)
+	}
 
-			schedLogger.Error("Failed to create a new scheduled task!",
+	if rollout != nil {
+		err = activateRollout(rollout, request.Rollout, response.TaskCount)
+		if err != nil {
+			schedLogger.Error("Failed to activate the rollout of the consignment!",
 				zap.String("Consignment ID", request.ConsignmentId),
-				zap.String("Tenant ID", request.TenantId),
-				zap.String("Device ID", deviceID),
 				zap.Error(err),
 			)
-			response.ErrorCount++
-		} else {
-			response.TaskCount++
-			if source == common.SchedulerRequestSourceRest {
-				response.TasksScheduled = append(
-					response.TasksScheduled, &pb.TaskInfo{
-						TaskId:   newTask.TaskInfo.TaskID.String(),
-						DeviceId: deviceID,
-						Status:   newTask.TaskInfo.Status,
-					})
-			}
-			schedLogger.Debug("Queued a scheduled task with the scheduler!",
-				zap.String("Consignment ID", request.ConsignmentId),
-				zap.String("Tenant ID", request.TenantId),
-				zap.String("Device ID", deviceID),
-				zap.String("Task ID", newTask.TaskInfo.TaskID.String()),
-			)
+			response.ErrorCount += response.PendingRolloutCount
+			response.PendingRolloutCount = 0
 		}
 	}
 
@@ -149,3 +203,58 @@ func handleSchedulerInputQueueRequest(request *pb.CreateScheduledTaskRequest,
 
 	return response, nil
 }
+
+// Create and schedule the task requested for the specified device, and record
+// the outcome in the specified response.
+func scheduleDeviceTask(request *pb.CreateScheduledTaskRequest,
+	loc *time.Location, deviceID string, source string,
+	response *pb.CreateScheduledTaskResponse) {
+	// Parse the provided task schedule and schedule the task.
+	newTask, err := NewScheduledTask(loc, deviceID, request).
+		ParseSchedule(request.Schedule).
+		ScheduleBounds(request.EndAt, request.MaxRuns).
+		Jitter(request.Jitter).
+		RetryPolicy(request.RetryPolicy).
+		ResponseTimeout(request.ResponseTimeout).
+		MisfirePolicy(request.MisfirePolicy).
+		Schedule()
+	if err != nil {
+		var scheduleErr *ScheduleError
+		switch {
+		case errors.As(err, &scheduleErr), err == db.ErrInvalidRequest,
+			err == ErrInvalidSchedulingUnit, err == ErrInvalidScheduleType,
+			err == ErrInvalidRetryPolicy, err == ErrInvalidResponseTimeout,
+			err == ErrInvalidMisfirePolicy, err == ErrInvalidEndAt,
+			err == ErrInvalidMaxRuns, err == ErrInvalidScheduleBound,
+			err == ErrInvalidJitter:
+			metrics.MetricCreateTaskBadRequests.Inc()
+
+		default:
+			metrics.MetricCreateTaskInternalErrors.Inc()
+		}
+
+		schedLogger.Error("Failed to create a new scheduled task!",
+			zap.String("Consignment ID", request.ConsignmentId),
+			zap.String("Tenant ID", request.TenantId),
+			zap.String("Device ID", deviceID),
+			zap.Error(err),
+		)
+		response.ErrorCount++
+	} else {
+		response.TaskCount++
+		if source == common.SchedulerRequestSourceRest {
+			response.TasksScheduled = append(
+				response.TasksScheduled, &pb.TaskInfo{
+					TaskId:   newTask.TaskInfo.TaskID.String(),
+					DeviceId: deviceID,
+					Status:   newTask.TaskInfo.Status,
+				})
+		}
+		schedLogger.Debug("Queued a scheduled task with the scheduler!",
+			zap.String("Consignment ID", request.ConsignmentId),
+			zap.String("Tenant ID", request.TenantId),
+			zap.String("Device ID", deviceID),
+			zap.String("Task ID", newTask.TaskInfo.TaskID.String()),
+		)
+	}
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"time"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/mqtt"
+	"go.uber.org/zap"
+)
+
+// ResponseTimeout - specifies the time within which the device must respond to
+// the task once it has been dispatched, as a duration string (e.g. "5m").
+func (s *ScheduledTask) ResponseTimeout(timeout string) *ScheduledTask {
+	if timeout == "" {
+		return s
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		schedLogger.Error("Invalid response timeout specified!",
+			zap.String("Specified timeout", timeout),
+		)
+		s.error = wrapOrError(s.error, ErrInvalidResponseTimeout)
+		return s
+	}
+
+	s.TaskInfo.ResponseTimeout = d
+	return s
+}
+
+// Periodically look for dispatched tasks for which the device did not respond
+// before the response deadline and mark them as timed out. Like the scheduler
+// daemon, this only runs on the scheduler instance holding the scheduler
+// daemon lease.
+func runResponseTimeoutSweeper() {
+	schedLogger.Info("Starting the response timeout sweeper!")
+
+	// Deadlines left over from an outage of the scheduler are picked up on
+	// startup, in the same way as scheduled runs.
+	oldestPartition := getCatchupStart(time.Now())
+
+	for {
+		select {
+		case <-time.After(getExecutionQuantum()):
+
+		case <-schedCtx.Done():
+			schedLogger.Info("Received signal to stop the response timeout sweeper!")
+			return
+		}
+
+		if !holdsSchedulerLease() {
+			continue
+		}
+
+		now := time.Now()
+		nextOldestPartition := now.UTC().Truncate(db.RunPartitionPeriod)
+		for partition := oldestPartition; !partition.After(now); partition = partition.Add(db.RunPartitionPeriod) {
+			for shard := 0; shard < db.RunPartitionShards; shard++ {
+				if !sweepResponseDeadlines(db.GetRunPartition(partition),
+					shard, now) && partition.Before(nextOldestPartition) {
+					nextOldestPartition = partition
+				}
+			}
+		}
+		oldestPartition = nextOldestPartition
+	}
+}
+
+// Time out tasks whose response deadlines within the specified shard of the
+// specified partition have passed. Returns whether all such deadlines were
+// processed.
+func sweepResponseDeadlines(deadlinePartition string, shard int,
+	now time.Time) bool {
+	var (
+		deadlines []*db.ResponseDeadline
+		nextPage  []byte
+		err       error
+	)
+
+	drained := true
+	for {
+		deadlines, nextPage, err = db.GetResponseDeadlines(deadlinePartition,
+			shard, now, nextPage)
+		if err != nil {
+			schedLogger.Error("Failed to query response deadlines from the scheduler database!",
+				zap.String("Deadline partition", deadlinePartition),
+				zap.Int("Shard", shard),
+				zap.Error(err),
+			)
+			return false
+		}
+
+		for _, deadline := range deadlines {
+			if schedCtx.Err() != nil {
+				return false
+			}
+
+			err = timeoutTask(deadline)
+			if err == nil {
+				err = deadline.RemoveResponseDeadline()
+			}
+			if err != nil {
+				drained = false
+			}
+		}
+
+		if len(nextPage) == 0 {
+			return drained
+		}
+	}
+}
+
+// Time out the task for the specified response deadline, if the device has not
+// responded to the dispatch of the task which the deadline belongs to. The task
+// is retried as per its retry policy and the service which requested the task
+// is notified.
+func timeoutTask(deadline *db.ResponseDeadline) error {
+	task, err := db.GetTaskByID(deadline.TaskID.String(),
+		deadline.DeviceID.String())
+	if err == db.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		schedLogger.Error("Failed to retrieve task",
+			zap.String("Task ID", deadline.TaskID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// Ignore the deadline if the device has responded or the task has since
+	// been dispatched again with a new deadline.
+	if task.Status != db.TaskStatusDispatched.String() ||
+		!task.ResponseDeadline.Equal(deadline.Deadline) {
+		return nil
+	}
+
+	schedLogger.Info("Device did not respond to the task in time!",
+		zap.String("Task ID", task.TaskID.String()),
+		zap.String("Device ID", task.DeviceID.String()),
+		zap.Time("Deadline", deadline.Deadline),
+	)
+
+	err = db.MarkTaskTimedOut(task)
+	if err != nil {
+		return err
+	}
+	_ = db.EndTaskRun(task, db.TaskStatusTimedOut.String(), "")
+
+	_, err = retryTask(task, db.TaskStatusTimedOut.String(),
+		db.TaskStatusTimedOut)
+	if err != nil {
+		return err
+	}
+
+	return publishDeviceEvent(mqtt.TaskResponsesTopic, &pb.DeviceEvent{
+		Version:       1,
+		ServiceId:     task.ServiceID,
+		DeviceId:      task.DeviceID.String(),
+		TaskId:        task.TaskID.String(),
+		ConsignmentId: task.ConsignmentID,
+		TenantId:      task.TenantID,
+		TaskStatus:    db.TaskStatusTimedOut.String(),
+		MessageId:     task.MessageId,
+		MessageType:   task.MessageType,
+	})
+}
@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/config"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/mqtt"
+	"go.uber.org/zap"
+)
+
+const (
+	// Task status used for the retry policy when a task could not be
+	// delivered to the device.
+	retryStatusUndelivered = "undelivered"
+
+	// Defaults used for retry policy settings that are not specified.
+	defaultRetryInitialBackoff    = 30 * time.Second
+	defaultRetryMaxBackoff        = 1 * time.Hour
+	defaultRetryBackoffMultiplier = 2.0
+)
+
+var (
+	DispatchFailureHandlerFunc = handleDispatchFailure
+
+	// Retry policies configured for registered services.
+	serviceRetryPolicies map[string]*config.RetryPolicy
+
+	// Task statuses that are retried if the retry policy doesn't specify any.
+	defaultRetryableStatuses = []string{"failed", "error", retryStatusUndelivered,
+		db.TaskStatusTimedOut.String()}
+)
+
+// Initialize the lookup table of retry policies configured for registered
+// services.
+func initRetryPolicies(cfgMgr *config.ConfigMgr) {
+	services := cfgMgr.GetServiceRegistrations()
+	serviceRetryPolicies = make(map[string]*config.RetryPolicy, len(*services))
+	for _, service := range *services {
+		if service.RetryPolicy != nil {
+			serviceRetryPolicies[service.ServiceId] = service.RetryPolicy
+		}
+	}
+}
+
+// Set the retry policy requested for the task.
+func (s *ScheduledTask) RetryPolicy(policy *pb.RetryPolicy) *ScheduledTask {
+	var err error
+
+	if policy == nil {
+		return s
+	}
+
+	s.TaskInfo.RetryMaxAttempts = int(policy.MaxAttempts)
+	s.TaskInfo.RetryBackoffMultiplier = policy.BackoffMultiplier
+	s.TaskInfo.RetryableStatuses = policy.RetryableStatuses
+
+	if policy.InitialBackoff != "" {
+		s.TaskInfo.RetryInitialBackoff, err = time.ParseDuration(policy.InitialBackoff)
+		if err != nil {
+			s.error = wrapOrError(s.error, ErrInvalidRetryPolicy)
+		}
+	}
+
+	if policy.MaxBackoff != "" {
+		s.TaskInfo.RetryMaxBackoff, err = time.ParseDuration(policy.MaxBackoff)
+		if err != nil {
+			s.error = wrapOrError(s.error, ErrInvalidRetryPolicy)
+		}
+	}
+
+	if s.TaskInfo.RetryInitialBackoff < 0 || s.TaskInfo.RetryMaxBackoff < 0 ||
+		s.TaskInfo.RetryBackoffMultiplier < 0 {
+		s.error = wrapOrError(s.error, ErrInvalidRetryPolicy)
+	}
+
+	return s
+}
+
+// Return the retry policy for the task. The retry policy configured for the
+// service which requested the task takes precedence over the default retry
+// policy configured for the scheduler. Any settings of the retry policy
+// requested for the task take precedence over both.
+func getRetryPolicy(task *db.Task) *config.RetryPolicy {
+	base, ok := serviceRetryPolicies[task.ServiceID]
+	if !ok {
+		base = &schedConfig.RetryPolicy
+	}
+
+	policy := *base
+	if task.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = task.RetryMaxAttempts
+	}
+	if task.RetryInitialBackoff > 0 {
+		policy.InitialBackoff = task.RetryInitialBackoff
+	}
+	if task.RetryMaxBackoff > 0 {
+		policy.MaxBackoff = task.RetryMaxBackoff
+	}
+	if task.RetryBackoffMultiplier > 0 {
+		policy.BackoffMultiplier = task.RetryBackoffMultiplier
+	}
+	if len(task.RetryableStatuses) > 0 {
+		policy.RetryableStatuses = task.RetryableStatuses
+	}
+	return &policy
+}
+
+// Check if the retry policy permits retrying a task in the specified status,
+// which has already been retried the specified number of times.
+func shouldRetry(policy *config.RetryPolicy, status string,
+	retryCount int) bool {
+	if retryCount+1 >= policy.MaxAttempts {
+		return false
+	}
+
+	retryableStatuses := policy.RetryableStatuses
+	if len(retryableStatuses) == 0 {
+		retryableStatuses = defaultRetryableStatuses
+	}
+	return slices.Contains(retryableStatuses, strings.ToLower(status))
+}
+
+// Calculate the delay before the next retry of a task that has already been
+// retried the specified number of times. The delay grows exponentially with
+// each retry, up to the maximum delay configured in the retry policy.
+func retryBackoff(policy *config.RetryPolicy, retryCount int) time.Duration {
+	initialBackoff := policy.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultRetryInitialBackoff
+	}
+
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	multiplier := policy.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = defaultRetryBackoffMultiplier
+	}
+
+	backoff := float64(initialBackoff) * math.Pow(multiplier, float64(retryCount))
+	if backoff > float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// Retry the task that failed with the specified status, if permitted by its
+// retry policy. The retry is scheduled as a scheduled run of the task after the
+// backoff delay. If the task is not retried, it is set to the specified final
+// status. Returns whether a retry was scheduled.
+func retryTask(task *db.Task, status string,
+	finalStatus db.TaskStatus) (bool, error) {
+	policy := getRetryPolicy(task)
+	if !shouldRetry(policy, status, task.RetryCount) {
+		schedLogger.Info("Giving up on the task as per its retry policy!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.String("Status", status),
+			zap.Int("Retry count", task.RetryCount),
+		)
+		return false, db.UpdateTaskStatus(task.TaskID.String(),
+			task.DeviceID.String(), task.TenantID, task.ConsignmentID,
+			finalStatus)
+	}
+
+	retryRun := db.NewScheduledRun(task)
+	retryRun.NextRun = time.Now().Add(retryBackoff(policy, task.RetryCount))
+	retryRun.IsRetry = true
+	err := retryRun.CreateScheduledRun()
+	if err != nil {
+		schedLogger.Error("Failed to schedule a retry for the task!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.Error(err),
+		)
+		return false, err
+	}
+
+	err = db.MarkTaskPendingRetry(task)
+	if err != nil {
+		return true, err
+	}
+
+	schedLogger.Info("Scheduled a retry for the task!",
+		zap.String("Task ID", task.TaskID.String()),
+		zap.String("Status", status),
+		zap.Int("Retry count", task.RetryCount),
+		zap.Time("Next Run", retryRun.NextRun),
+	)
+	return true, nil
+}
+
+// Process a task that the dispatch queue watcher could not deliver to the
+// device. The task is retried as per its retry policy. If the scheduler gives
+// up on the task, the service which requested the task is notified.
+func handleDispatchFailure(message *pb.ServiceMessage) error {
+	task, err := db.GetTaskByID(message.TaskId, message.DeviceId)
+	if err != nil {
+		schedLogger.Error("Failed to retrieve task",
+			zap.String("Task ID", message.TaskId),
+			zap.String("Device ID", message.DeviceId),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// Cancelled or paused tasks and cancellation messages are not retried.
+	if task.Status == db.TaskStatusCancelled.String() ||
+		task.Status == db.TaskStatusPaused.String() ||
+		message.MessageType == common.CancelTaskMessageType {
+		schedLogger.Info("Dropping an undelivered message for an inactive task!",
+			zap.String("Task ID", message.TaskId),
+			zap.String("Device ID", message.DeviceId),
+		)
+		return nil
+	}
+
+	retried, err := retryTask(task, retryStatusUndelivered,
+		db.TaskStatusFailed)
+	if err != nil || retried {
+		return err
+	}
+
+	return publishDeviceEvent(mqtt.TaskResponsesTopic, &pb.DeviceEvent{
+		Version:       1,
+		ServiceId:     task.ServiceID,
+		DeviceId:      message.DeviceId,
+		TaskId:        message.TaskId,
+		ConsignmentId: task.ConsignmentID,
+		TenantId:      task.TenantID,
+		TaskStatus:    db.TaskStatusFailed.String(),
+		MessageId:     task.MessageId,
+		MessageType:   task.MessageType,
+	})
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/config"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &config.RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoff:    10 * time.Second,
+		MaxBackoff:        time.Minute,
+		BackoffMultiplier: 3,
+	}
+
+	expected := []time.Duration{10 * time.Second, 30 * time.Second,
+		time.Minute, time.Minute}
+	for retryCount, backoff := range expected {
+		if got := retryBackoff(policy, retryCount); got != backoff {
+			t.Errorf("Unexpected backoff %v for retry %d, expected %v\n",
+				got, retryCount, backoff)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	policy := &config.RetryPolicy{
+		MaxAttempts:       3,
+		RetryableStatuses: []string{retryStatusUndelivered},
+	}
+
+	if !shouldRetry(policy, retryStatusUndelivered, 1) {
+		t.Errorf("Expected the second retry to be permitted\n")
+	}
+	if shouldRetry(policy, retryStatusUndelivered, 2) {
+		t.Errorf("Expected retries to stop after the maximum attempts\n")
+	}
+	if shouldRetry(policy, "failed", 0) {
+		t.Errorf("Expected statuses that are not retryable to not be retried\n")
+	}
+}
+
+func TestGetRetryPolicyPartial(t *testing.T) {
+	saved := schedConfig
+	schedConfig = &config.SchedulerConfig{
+		RetryPolicy: config.RetryPolicy{
+			MaxAttempts:       3,
+			InitialBackoff:    30 * time.Second,
+			MaxBackoff:        10 * time.Minute,
+			BackoffMultiplier: 2,
+		},
+	}
+	defer func() { schedConfig = saved }()
+
+	task := &db.Task{
+		RetryInitialBackoff: time.Minute,
+		RetryableStatuses:   []string{"failed"},
+	}
+	policy := getRetryPolicy(task)
+	if policy.MaxAttempts != 3 || policy.MaxBackoff != 10*time.Minute ||
+		policy.BackoffMultiplier != 2 {
+		t.Errorf("Expected unset settings to use the default policy, got %+v\n",
+			policy)
+	}
+	if policy.InitialBackoff != time.Minute ||
+		len(policy.RetryableStatuses) != 1 {
+		t.Errorf("Expected the settings requested for the task to be used, got %+v\n",
+			policy)
+	}
+	if schedConfig.RetryPolicy.InitialBackoff != 30*time.Second {
+		t.Errorf("Expected the default policy to be left unchanged\n")
+	}
+}
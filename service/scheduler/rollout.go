@@ -0,0 +1,493 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"github.com/hpinc/krypton-scheduler/service/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// A stage of a rollout, along with the time to wait after the stage has
+// started before advancing to the next stage.
+type rolloutStage struct {
+	percentage uint32
+	wait       time.Duration
+}
+
+// Parse and validate the stages of the specified rollout policy. The
+// percentages of the stages must increase from one stage to the next up to
+// 100, and every stage but the last must specify the time to wait before the
+// next stage.
+func parseRolloutPolicy(policy *pb.RolloutPolicy) ([]rolloutStage, error) {
+	if len(policy.Stages) == 0 ||
+		policy.MaxFailureRate < 0 || policy.MaxFailureRate > 1 ||
+		policy.MinResponseRate < 0 || policy.MinResponseRate > 1 {
+		return nil, ErrInvalidRolloutPolicy
+	}
+
+	stages := make([]rolloutStage, len(policy.Stages))
+	var previous uint32
+	for i, stage := range policy.Stages {
+		if stage.Percentage <= previous || stage.Percentage > 100 {
+			return nil, ErrInvalidRolloutPolicy
+		}
+		previous = stage.Percentage
+		stages[i].percentage = stage.Percentage
+
+		if i == len(policy.Stages)-1 {
+			break
+		}
+		wait, err := time.ParseDuration(stage.Wait)
+		if err != nil || wait <= 0 {
+			return nil, ErrInvalidRolloutPolicy
+		}
+		stages[i].wait = wait
+	}
+
+	if previous != 100 {
+		return nil, ErrInvalidRolloutPolicy
+	}
+	return stages, nil
+}
+
+// Split the specified devices into the stages of a rollout. Each stage holds
+// the devices added by the stage, rounding up, so that every stage but the
+// first may be empty for small numbers of devices.
+func splitRolloutDevices(deviceIDs []string,
+	stages []rolloutStage) [][]string {
+	stageDevices := make([][]string, len(stages))
+
+	start := 0
+	for i, stage := range stages {
+		end := int(math.Ceil(float64(len(deviceIDs)) *
+			float64(stage.percentage) / 100))
+		stageDevices[i] = deviceIDs[start:end]
+		start = end
+	}
+	return stageDevices
+}
+
+// Start a staged rollout of the tasks in the specified request. The devices in
+// the later stages of the rollout are recorded, to be scheduled as the rollout
+// advances. Returns the rollout, which remains in the starting status until
+// it is activated, the devices in the first stage and the number of devices
+// in the later stages.
+func startRollout(request *pb.CreateScheduledTaskRequest) (*db.Rollout,
+	[]string, int, error) {
+	stages, err := parseRolloutPolicy(request.Rollout)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if request.DeviceIds[0] == common.BroadcastDeviceID ||
+		request.ConsignmentId == "" {
+		return nil, nil, 0, ErrInvalidRolloutPolicy
+	}
+
+	stageDevices := splitRolloutDevices(request.DeviceIds, stages)
+	if len(stages) == 1 {
+		return nil, stageDevices[0], 0, nil
+	}
+
+	// The request is stored without its device IDs, which are recorded
+	// separately for each stage.
+	stored := proto.Clone(request).(*pb.CreateScheduledTaskRequest)
+	stored.DeviceIds = nil
+	encoded, err := proto.Marshal(stored)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	rollout := &db.Rollout{
+		TenantID:      request.TenantId,
+		ConsignmentID: request.ConsignmentId,
+		Request:       encoded,
+	}
+	err = rollout.CreateRollout(stageDevices[1:])
+	if err == db.ErrDuplicateEntry {
+		return nil, nil, 0, ErrRolloutExists
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return rollout, stageDevices[0],
+		len(request.DeviceIds) - len(stageDevices[0]), nil
+}
+
+// Activate the rollout once the specified number of tasks have been scheduled
+// for the devices in its first stage. The rollout advances to its next stage
+// once the first stage is over. If the rollout cannot be activated, it is
+// removed along with its devices, so that no devices are left waiting for a
+// rollout which never advances.
+func activateRollout(rollout *db.Rollout, policy *pb.RolloutPolicy,
+	stageTasks uint32) error {
+	stages, err := parseRolloutPolicy(policy)
+	if err == nil {
+		_, _, rollout.StageResponses, err = getRolloutResponses(rollout)
+	}
+	if err == nil {
+		rollout.Status = db.RolloutStatusInProgress
+		rollout.NextStageAt = time.Now().Add(stages[0].wait)
+		rollout.StageTasks = int(stageTasks)
+
+		var applied bool
+		applied, err = rollout.UpdateRollout(0, db.RolloutStatusStarting)
+		if err == nil && !applied {
+			err = db.ErrNotFound
+		}
+	}
+	if err != nil {
+		_ = rollout.RemoveRollout()
+		return err
+	}
+	return nil
+}
+
+// Periodically advance the rollouts whose current stage is over, provided that
+// the failure rate of the tasks in their consignment is acceptable. Like the
+// scheduler daemon, this only runs on the scheduler instance holding the
+// scheduler daemon lease.
+func runRolloutAdvancer() {
+	schedLogger.Info("Starting the rollout advancer!")
+
+	for {
+		select {
+		case <-time.After(getExecutionQuantum()):
+
+		case <-schedCtx.Done():
+			schedLogger.Info("Received signal to stop the rollout advancer!")
+			return
+		}
+
+		if !holdsSchedulerLease() {
+			continue
+		}
+
+		var nextPage []byte
+		for {
+			rollouts, page, err := db.GetRollouts(nextPage)
+			if err != nil {
+				schedLogger.Error("Failed to query rollouts from the scheduler database!",
+					zap.Error(err),
+				)
+				break
+			}
+
+			now := time.Now()
+			for _, rollout := range rollouts {
+				if schedCtx.Err() != nil {
+					return
+				}
+				if rollout.Status == db.RolloutStatusInProgress &&
+					!rollout.NextStageAt.After(now) {
+					advanceRollout(rollout)
+				}
+			}
+
+			if len(page) == 0 {
+				break
+			}
+			nextPage = page
+		}
+	}
+}
+
+// Return the number of failures and responses for the tasks in the specified
+// consignment since the rollout of the consignment was last resumed.
+func rolloutResponses(rollout *db.Rollout,
+	summary *db.ConsignmentSummary) (int64, int64) {
+	failures := summary.StatusCounts[db.TaskStatusFailed.String()] +
+		summary.StatusCounts[db.TaskStatusTimedOut.String()]
+	responses := failures +
+		summary.StatusCounts[db.TaskStatusCompleted.String()] +
+		summary.StatusCounts[db.TaskStatusScheduleExhausted.String()]
+
+	failures -= rollout.BaselineFailures
+	responses -= rollout.BaselineResponses
+	if failures < 0 {
+		failures = 0
+	}
+	if responses < failures {
+		responses = failures
+	}
+	return failures, responses
+}
+
+// Get the number of failures and responses for the tasks in the consignment
+// of the specified rollout, as for rolloutResponses, along with the total
+// number of responses in the consignment.
+func getRolloutResponses(rollout *db.Rollout) (int64, int64, int64, error) {
+	summary, err := db.GetConsignmentSummary(rollout.TenantID,
+		rollout.ConsignmentID)
+	if err == db.ErrNotFound {
+		return 0, 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	failures, responses := rolloutResponses(rollout, summary)
+	_, total := rolloutResponses(&db.Rollout{}, summary)
+	return failures, responses, total, nil
+}
+
+// Return the number of responses required from the specified number of tasks
+// scheduled in a stage of a rollout for the rollout to advance, given the
+// minimum response rate of the rollout. At least one response is required
+// from a stage for which tasks were scheduled.
+func requiredStageResponses(stageTasks int, minResponseRate float64) int64 {
+	if stageTasks <= 0 {
+		return 0
+	}
+
+	required := int64(math.Ceil(float64(stageTasks) * minResponseRate))
+	if required < 1 {
+		required = 1
+	}
+	return required
+}
+
+// Check if the failure rate of the responses exceeds the maximum failure rate
+// of the rollout.
+func exceedsFailureRate(failures int64, responses int64,
+	maxFailureRate float64) bool {
+	return responses != 0 &&
+		float64(failures)/float64(responses) > maxFailureRate
+}
+
+// Advance the rollout to its next stage and schedule tasks for the devices in
+// that stage. If the failure rate of the tasks in the consignment exceeds the
+// maximum failure rate of the rollout, the rollout and the consignment are
+// paused instead. The rollout waits at its current stage until enough of the
+// tasks in the stage have been responded to, and until tasks have been
+// scheduled for every device in the stage. The rollout completes once tasks
+// have been scheduled for every device in its last stage.
+func advanceRollout(rollout *db.Rollout) {
+	request := &pb.CreateScheduledTaskRequest{}
+	err := proto.Unmarshal(rollout.Request, request)
+	if err == nil && request.Rollout == nil {
+		err = ErrInvalidRolloutPolicy
+	}
+	var stages []rolloutStage
+	if err == nil {
+		stages, err = parseRolloutPolicy(request.Rollout)
+	}
+	if err != nil {
+		schedLogger.Error("Failed to decode the request stored for the rollout!",
+			zap.String("Consignment ID", rollout.ConsignmentID),
+			zap.String("Tenant ID", rollout.TenantID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	stage, status := rollout.Stage, rollout.Status
+
+	// Devices in the current stage for which tasks could not be scheduled
+	// when the stage started are retried before the rollout advances.
+	scheduled, failed := scheduleRolloutStage(rollout, request)
+	if scheduled != 0 {
+		rollout.StageTasks += int(scheduled)
+		applied, err := rollout.UpdateRollout(stage, status)
+		if err != nil || !applied {
+			return
+		}
+	}
+	if failed != 0 {
+		schedLogger.Error("Failed to schedule tasks for every device in the current stage of the rollout!",
+			zap.String("Consignment ID", rollout.ConsignmentID),
+			zap.String("Tenant ID", rollout.TenantID),
+			zap.Int("Stage", rollout.Stage),
+			zap.Uint32("Failures", failed),
+		)
+		return
+	}
+
+	if rollout.Stage == len(stages)-1 {
+		rollout.Status = db.RolloutStatusCompleted
+		rollout.NextStageAt = time.Time{}
+		applied, err := rollout.UpdateRollout(stage, status)
+		if err == nil && applied {
+			_ = rollout.RemoveRolloutDevices()
+		}
+		return
+	}
+
+	failures, responses, total, err := getRolloutResponses(rollout)
+	if err != nil {
+		return
+	}
+
+	if exceedsFailureRate(failures, responses,
+		request.Rollout.MaxFailureRate) {
+		schedLogger.Info("Pausing the rollout as the failure rate is too high!",
+			zap.String("Consignment ID", rollout.ConsignmentID),
+			zap.String("Tenant ID", rollout.TenantID),
+			zap.Int("Stage", rollout.Stage),
+			zap.Int64("Failures", failures),
+			zap.Int64("Responses", responses),
+		)
+		rollout.Status = db.RolloutStatusPaused
+		applied, err := rollout.UpdateRollout(stage, status)
+		if err != nil || !applied {
+			return
+		}
+		metrics.MetricRolloutsPaused.Inc()
+
+		_, _ = pauseConsignment(rollout.TenantID, rollout.ConsignmentID)
+		return
+	}
+
+	if total-rollout.StageResponses < requiredStageResponses(
+		rollout.StageTasks, request.Rollout.MinResponseRate) {
+		schedLogger.Debug("Waiting for responses from the current stage of the rollout!",
+			zap.String("Consignment ID", rollout.ConsignmentID),
+			zap.String("Tenant ID", rollout.TenantID),
+			zap.Int("Stage", rollout.Stage),
+			zap.Int("Tasks", rollout.StageTasks),
+			zap.Int64("Responses", total-rollout.StageResponses),
+		)
+		return
+	}
+
+	// The rollout is advanced before tasks are scheduled for the devices in
+	// the next stage, so that tasks are never scheduled twice for a device.
+	// The last stage is over as soon as tasks have been scheduled for every
+	// device in it.
+	rollout.Stage++
+	rollout.StageTasks = 0
+	rollout.StageResponses = total
+	rollout.NextStageAt = time.Now().Add(stages[rollout.Stage].wait)
+	applied, err := rollout.UpdateRollout(stage, status)
+	if err != nil || !applied {
+		return
+	}
+	metrics.MetricRolloutStagesAdvanced.Inc()
+
+	scheduled, failed = scheduleRolloutStage(rollout, request)
+	if scheduled != 0 {
+		rollout.StageTasks = int(scheduled)
+		_, _ = rollout.UpdateRollout(rollout.Stage, rollout.Status)
+	}
+	schedLogger.Info("Advanced the rollout to its next stage!",
+		zap.String("Consignment ID", rollout.ConsignmentID),
+		zap.String("Tenant ID", rollout.TenantID),
+		zap.Int("Stage", rollout.Stage),
+		zap.Uint32("Scheduled", scheduled),
+		zap.Uint32("Failures", failed),
+	)
+}
+
+// Schedule tasks for the devices remaining in the current stage of the rollout.
+// Each device is removed from the stage once a task has been scheduled for it,
+// so that the devices for which tasks could not be scheduled are retried.
+// Returns the number of tasks scheduled and the number of devices for which
+// tasks could not be scheduled.
+func scheduleRolloutStage(rollout *db.Rollout,
+	request *pb.CreateScheduledTaskRequest) (uint32, uint32) {
+	response := &pb.CreateScheduledTaskResponse{}
+
+	loc, err := loadLocation(request.Timezone)
+	if err != nil {
+		return 0, 1
+	}
+
+	var nextPage []byte
+	for {
+		devices, page, err := rollout.GetRolloutDevices(rollout.Stage,
+			nextPage)
+		if err != nil {
+			response.ErrorCount++
+			break
+		}
+
+		for _, deviceID := range devices {
+			taskCount := response.TaskCount
+			scheduleDeviceTask(request, loc, deviceID,
+				common.SchedulerRequestSourceEvent, response)
+			if response.TaskCount != taskCount {
+				_ = rollout.RemoveRolloutDevice(rollout.Stage, deviceID)
+			}
+		}
+
+		if len(page) == 0 {
+			break
+		}
+		nextPage = page
+	}
+
+	return response.TaskCount, response.ErrorCount
+}
+
+// Pause the rollout of the specified consignment, if it has one in progress,
+// so that no further stages of the rollout start while the consignment is
+// paused.
+func pauseRollout(tenantID string, consignmentID string) {
+	rollout, err := db.GetRollout(tenantID, consignmentID)
+	if err != nil || rollout.Status != db.RolloutStatusInProgress {
+		return
+	}
+
+	rollout.Status = db.RolloutStatusPaused
+	_, _ = rollout.UpdateRollout(rollout.Stage, db.RolloutStatusInProgress)
+}
+
+// Resume the paused rollout of the specified consignment. The current stage of
+// the rollout starts afresh, and only the failures and responses from then on
+// count towards the failure rate of the rollout.
+func resumeRollout(tenantID string, consignmentID string) {
+	rollout, err := db.GetRollout(tenantID, consignmentID)
+	if err != nil || rollout.Status != db.RolloutStatusPaused {
+		return
+	}
+
+	request := &pb.CreateScheduledTaskRequest{}
+	err = proto.Unmarshal(rollout.Request, request)
+	if err != nil || request.Rollout == nil {
+		return
+	}
+	stages, err := parseRolloutPolicy(request.Rollout)
+	if err != nil {
+		return
+	}
+
+	// Responses are counted without the previous baseline, which is replaced.
+	rollout.BaselineFailures, rollout.BaselineResponses = 0, 0
+	failures, responses, _, err := getRolloutResponses(rollout)
+	if err != nil {
+		return
+	}
+
+	rollout.Status = db.RolloutStatusInProgress
+	rollout.NextStageAt = time.Now().Add(stages[rollout.Stage].wait)
+	rollout.BaselineFailures = failures
+	rollout.BaselineResponses = responses
+	_, _ = rollout.UpdateRollout(rollout.Stage, db.RolloutStatusPaused)
+}
+
+// Cancel the rollout of the specified consignment, if it has not completed, so
+// that no tasks are scheduled for the devices in its later stages.
+func cancelRollout(tenantID string, consignmentID string) {
+	rollout, err := db.GetRollout(tenantID, consignmentID)
+	if err != nil {
+		return
+	}
+
+	status := rollout.Status
+	switch status {
+	case db.RolloutStatusInProgress, db.RolloutStatusPaused:
+	default:
+		return
+	}
+
+	rollout.Status = db.RolloutStatusCancelled
+	applied, err := rollout.UpdateRollout(rollout.Stage, status)
+	if err == nil && applied {
+		_ = rollout.RemoveRolloutDevices()
+	}
+}
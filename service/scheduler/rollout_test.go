@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+// Return a rollout policy with the specified stage percentages, waiting an hour
+// between stages.
+func testRolloutPolicy(percentages ...uint32) *pb.RolloutPolicy {
+	policy := &pb.RolloutPolicy{MaxFailureRate: 0.05}
+	for _, percentage := range percentages {
+		policy.Stages = append(policy.Stages,
+			&pb.RolloutStage{Percentage: percentage, Wait: "1h"})
+	}
+	return policy
+}
+
+func TestParseRolloutPolicy(t *testing.T) {
+	stages, err := parseRolloutPolicy(testRolloutPolicy(1, 10, 50, 100))
+	if err != nil || len(stages) != 4 || stages[0].wait != time.Hour ||
+		stages[3].percentage != 100 {
+		t.Errorf("Unexpected stages %+v (%v)\n", stages, err)
+	}
+
+	// The last stage does not need to wait.
+	policy := testRolloutPolicy(10, 100)
+	policy.Stages[1].Wait = ""
+	_, err = parseRolloutPolicy(policy)
+	if err != nil {
+		t.Errorf("Unexpected error for a last stage without a wait: %v\n", err)
+	}
+
+	invalid := map[string]*pb.RolloutPolicy{
+		"NoStages":          {},
+		"NotIncreasing":     testRolloutPolicy(10, 10, 100),
+		"NotEndingAt100":    testRolloutPolicy(10, 50),
+		"Over100":           testRolloutPolicy(10, 150),
+		"NegativeRate":      {Stages: testRolloutPolicy(100).Stages, MaxFailureRate: -1},
+		"RateOverOne":       {Stages: testRolloutPolicy(100).Stages, MaxFailureRate: 2},
+		"ResponseRateOver1": {Stages: testRolloutPolicy(100).Stages, MinResponseRate: 1.5},
+		"MissingWait":       {Stages: []*pb.RolloutStage{{Percentage: 10}, {Percentage: 100}}},
+		"InvalidWait":       {Stages: []*pb.RolloutStage{{Percentage: 10, Wait: "soon"}, {Percentage: 100}}},
+		"ZeroPercentStage":  testRolloutPolicy(0, 100),
+		"DecreasingPercent": testRolloutPolicy(50, 10, 100),
+	}
+	for name, policy := range invalid {
+		_, err = parseRolloutPolicy(policy)
+		if err != ErrInvalidRolloutPolicy {
+			t.Errorf("%s: expected an invalid rollout policy error, got %v\n",
+				name, err)
+		}
+	}
+}
+
+func TestSplitRolloutDevices(t *testing.T) {
+	stages, _ := parseRolloutPolicy(testRolloutPolicy(1, 10, 50, 100))
+
+	tests := []struct {
+		devices  int
+		expected []int
+	}{
+		{1000, []int{10, 90, 400, 500}},
+		{3, []int{1, 0, 1, 1}},
+		{1, []int{1, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		var deviceIDs []string
+		for i := 0; i < test.devices; i++ {
+			deviceIDs = append(deviceIDs, fmt.Sprintf("device-%d", i))
+		}
+
+		stageDevices := splitRolloutDevices(deviceIDs, stages)
+		for i, devices := range stageDevices {
+			if len(devices) != test.expected[i] {
+				t.Errorf("%d devices: expected %v devices per stage, got %d in stage %d\n",
+					test.devices, test.expected, len(devices), i)
+			}
+		}
+	}
+}
+
+func TestRolloutResponses(t *testing.T) {
+	summary := &db.ConsignmentSummary{StatusCounts: map[string]int64{
+		db.TaskStatusCompleted.String():  90,
+		db.TaskStatusFailed.String():     6,
+		db.TaskStatusTimedOut.String():   4,
+		db.TaskStatusDispatched.String(): 50,
+	}}
+
+	failures, responses := rolloutResponses(&db.Rollout{}, summary)
+	if failures != 10 || responses != 100 {
+		t.Errorf("Expected 10 failures of 100 responses, got %d of %d\n",
+			failures, responses)
+	}
+	if !exceedsFailureRate(failures, responses, 0.05) ||
+		exceedsFailureRate(failures, responses, 0.1) {
+		t.Errorf("Unexpected failure rate check for %d of %d\n", failures,
+			responses)
+	}
+
+	// Only failures and responses since the rollout was resumed count.
+	failures, responses = rolloutResponses(&db.Rollout{BaselineFailures: 9,
+		BaselineResponses: 60}, summary)
+	if failures != 1 || responses != 40 {
+		t.Errorf("Expected 1 failure of 40 responses, got %d of %d\n",
+			failures, responses)
+	}
+
+	if exceedsFailureRate(0, 0, 0) {
+		t.Errorf("Expected no responses to not exceed the failure rate\n")
+	}
+}
+
+func TestRequiredStageResponses(t *testing.T) {
+	tests := []struct {
+		stageTasks int
+		rate       float64
+		expected   int64
+	}{
+		{0, 0.5, 0},
+		{10, 0, 1},
+		{10, 0.5, 5},
+		{10, 0.25, 3},
+		{10, 1, 10},
+	}
+
+	for _, test := range tests {
+		required := requiredStageResponses(test.stageTasks, test.rate)
+		if required != test.expected {
+			t.Errorf("%d tasks at %v: expected %d responses, got %d\n",
+				test.stageTasks, test.rate, test.expected, required)
+		}
+	}
+}
+
+func TestStartRollout_NoConsignment(t *testing.T) {
+	_, _, _, err := startRollout(&pb.CreateScheduledTaskRequest{
+		DeviceIds: []string{"device1", "device2"},
+		Rollout:   testRolloutPolicy(50, 100),
+	})
+	if err != ErrInvalidRolloutPolicy {
+		t.Errorf("Expected an invalid rollout policy error, got %v\n", err)
+	}
+}
@@ -0,0 +1,777 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Layouts of the DATE-TIME and DATE values of iCalendar properties.
+	icalDateTimeLayout    = "20060102T150405"
+	icalUTCDateTimeLayout = "20060102T150405Z"
+	icalDateLayout        = "20060102"
+
+	// Maximum number of consecutive periods of a recurrence rule examined
+	// without finding an occurrence. Rules which produce no occurrence within
+	// this many periods are treated as having no further occurrences.
+	maxRRuleEmptyPeriods = 100000
+)
+
+// Frequency of an iCalendar (RFC 5545) recurrence rule.
+type rruleFrequency int
+
+const (
+	rruleSecondly rruleFrequency = iota + 1
+	rruleMinutely
+	rruleHourly
+	rruleDaily
+	rruleWeekly
+	rruleMonthly
+	rruleYearly
+)
+
+var rruleFrequencies = map[string]rruleFrequency{
+	"SECONDLY": rruleSecondly,
+	"MINUTELY": rruleMinutely,
+	"HOURLY":   rruleHourly,
+	"DAILY":    rruleDaily,
+	"WEEKLY":   rruleWeekly,
+	"MONTHLY":  rruleMonthly,
+	"YEARLY":   rruleYearly,
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// A day of the week in the BYDAY part of a recurrence rule, optionally with
+// an ordinal within the month or year, e.g. 2TU (the second Tuesday) or -1FR
+// (the last Friday).
+type rruleWeekday struct {
+	weekday time.Weekday
+	n       int
+}
+
+// A parsed iCalendar recurrence rule (RRULE). The BYWEEKNO and BYYEARDAY rule
+// parts are not supported.
+type recurrenceRule struct {
+	freq       rruleFrequency
+	interval   int
+	count      int
+	until      time.Time
+	byMonth    []int
+	byMonthDay []int
+	byDay      []rruleWeekday
+	byHour     []int
+	byMinute   []int
+	bySecond   []int
+	bySetPos   []int
+	weekStart  time.Weekday
+}
+
+// A parsed iCalendar recurrence set - the start of the recurrence (DTSTART),
+// the recurrence rule (RRULE) and the excluded occurrences (EXDATE).
+type recurrenceSet struct {
+	dtstart time.Time
+	rule    *recurrenceRule
+	exdates []time.Time
+}
+
+// Error encountered while parsing a content line of a recurrence set, along
+// with the index of the offending line.
+type rruleLineError struct {
+	line int
+	err  error
+}
+
+func newRRuleLineError(line int, format string, args ...interface{}) error {
+	return &rruleLineError{
+		line: line,
+		err: fmt.Errorf("%w: %s", ErrRRuleParseFailure,
+			fmt.Sprintf(format, args...)),
+	}
+}
+
+func (e *rruleLineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rruleLineError) Unwrap() error {
+	return e.err
+}
+
+// Parse the content lines of an iCalendar recurrence set. Supported content
+// lines are DTSTART, RRULE and EXDATE; a line consisting of the recurrence
+// rule parts alone (e.g. FREQ=DAILY) is treated as the RRULE. DATE-TIME values
+// without a TZID parameter or UTC designator are in the specified location.
+// If DTSTART is not specified, the recurrence starts at the specified time.
+func parseRecurrenceSet(lines []string, loc *time.Location,
+	defaultStart time.Time) (*recurrenceSet, error) {
+	var (
+		set        recurrenceSet
+		ruleLine   int
+		untilValue string
+		untilDate  bool
+	)
+
+	for i, line := range lines {
+		name, params, value, err := parseICalContentLine(line)
+		if err != nil {
+			return nil, newRRuleLineError(i, "%v", err)
+		}
+
+		switch name {
+		case "DTSTART":
+			if !set.dtstart.IsZero() {
+				return nil, newRRuleLineError(i, "duplicate DTSTART")
+			}
+			set.dtstart, _, err = parseICalTime(value, params, loc)
+			if err != nil {
+				return nil, newRRuleLineError(i, "invalid DTSTART: %v", err)
+			}
+
+		case "RRULE":
+			if set.rule != nil {
+				return nil, newRRuleLineError(i, "duplicate RRULE")
+			}
+			set.rule, untilValue, err = parseRecurrenceRule(value)
+			if err != nil {
+				return nil, newRRuleLineError(i, "%v", err)
+			}
+			ruleLine = i
+
+		case "EXDATE":
+			for _, exdate := range strings.Split(value, ",") {
+				t, _, err := parseICalTime(exdate, params, loc)
+				if err != nil {
+					return nil, newRRuleLineError(i, "invalid EXDATE: %v",
+						err)
+				}
+				set.exdates = append(set.exdates, t)
+			}
+
+		default:
+			return nil, newRRuleLineError(i, "unsupported property %s", name)
+		}
+	}
+
+	if set.rule == nil {
+		return nil, newRRuleLineError(len(lines), "no RRULE specified")
+	}
+	if set.dtstart.IsZero() {
+		set.dtstart = defaultStart.In(loc).Truncate(time.Second)
+	}
+
+	// UNTIL is interpreted in the timezone of DTSTART. A DATE value includes
+	// all occurrences on that date.
+	if untilValue != "" {
+		var err error
+		set.rule.until, untilDate, err = parseICalTime(untilValue, nil,
+			set.dtstart.Location())
+		if err != nil {
+			return nil, newRRuleLineError(ruleLine, "invalid UNTIL: %v", err)
+		}
+		if untilDate {
+			set.rule.until = set.rule.until.AddDate(0, 0, 1).
+				Add(-time.Nanosecond)
+		}
+	}
+
+	// Ordinals may only be specified for days of the week in monthly and
+	// yearly rules.
+	if set.rule.freq != rruleMonthly && set.rule.freq != rruleYearly {
+		for _, day := range set.rule.byDay {
+			if day.n != 0 {
+				return nil, newRRuleLineError(ruleLine,
+					"BYDAY ordinals require a MONTHLY or YEARLY frequency")
+			}
+		}
+	}
+
+	return &set, nil
+}
+
+// Split an iCalendar content line into its property name, parameters and
+// value. A line without a property name is treated as an RRULE value.
+func parseICalContentLine(line string) (string, map[string]string, string,
+	error) {
+	if strings.HasPrefix(strings.ToUpper(line), "FREQ=") {
+		return "RRULE", nil, line, nil
+	}
+
+	nameAndParams, value, ok := strings.Cut(line, ":")
+	if !ok || value == "" {
+		return "", nil, "", errors.New("expected NAME:VALUE")
+	}
+
+	parts := strings.Split(nameAndParams, ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		key, paramValue, ok := strings.Cut(param, "=")
+		if !ok {
+			return "", nil, "", fmt.Errorf("invalid parameter %s", param)
+		}
+		params[strings.ToUpper(key)] = paramValue
+	}
+	return strings.ToUpper(parts[0]), params, value, nil
+}
+
+// Parse an iCalendar DATE or DATE-TIME value. Returns true if the value is a
+// DATE, which is interpreted as midnight at the start of the date.
+func parseICalTime(value string, params map[string]string,
+	loc *time.Location) (time.Time, bool, error) {
+	if tzid, ok := params["TZID"]; ok {
+		var err error
+		loc, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(icalUTCDateTimeLayout, value)
+		return t, false, err
+
+	case len(value) == len(icalDateLayout):
+		t, err := time.ParseInLocation(icalDateLayout, value, loc)
+		return t, true, err
+	}
+
+	t, err := time.ParseInLocation(icalDateTimeLayout, value, loc)
+	return t, false, err
+}
+
+// Parse the value of an RRULE property. The UNTIL rule part is returned
+// unparsed, as it is interpreted in the timezone of DTSTART.
+func parseRecurrenceRule(value string) (*recurrenceRule, string, error) {
+	rule := &recurrenceRule{interval: 1, weekStart: time.Monday}
+	var until string
+
+	for _, part := range strings.Split(value, ";") {
+		key, partValue, ok := strings.Cut(part, "=")
+		if !ok || partValue == "" {
+			return nil, "", fmt.Errorf("invalid rule part %s", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.freq, ok = rruleFrequencies[strings.ToUpper(partValue)]
+			if !ok {
+				err = errors.New("unsupported FREQ")
+			}
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(partValue)
+			if err == nil && rule.interval <= 0 {
+				err = errors.New("INTERVAL must be positive")
+			}
+		case "COUNT":
+			rule.count, err = strconv.Atoi(partValue)
+			if err == nil && rule.count <= 0 {
+				err = errors.New("COUNT must be positive")
+			}
+		case "UNTIL":
+			until = partValue
+		case "BYMONTH":
+			rule.byMonth, err = parseRRuleInts(partValue, 1, 12, false)
+		case "BYMONTHDAY":
+			rule.byMonthDay, err = parseRRuleInts(partValue, 1, 31, true)
+		case "BYHOUR":
+			rule.byHour, err = parseRRuleInts(partValue, 0, 23, false)
+		case "BYMINUTE":
+			rule.byMinute, err = parseRRuleInts(partValue, 0, 59, false)
+		case "BYSECOND":
+			rule.bySecond, err = parseRRuleInts(partValue, 0, 59, false)
+		case "BYSETPOS":
+			rule.bySetPos, err = parseRRuleInts(partValue, 1, 366, true)
+		case "BYDAY":
+			rule.byDay, err = parseRRuleWeekdays(partValue)
+		case "WKST":
+			rule.weekStart, ok = rruleWeekdays[strings.ToUpper(partValue)]
+			if !ok {
+				err = errors.New("invalid day of the week")
+			}
+		default:
+			err = errors.New("unsupported rule part")
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s: %v", key, err)
+		}
+	}
+
+	if rule.freq == 0 {
+		return nil, "", errors.New("FREQ must be specified")
+	}
+	if rule.count != 0 && until != "" {
+		return nil, "", errors.New("COUNT and UNTIL cannot both be specified")
+	}
+	return rule, until, nil
+}
+
+// Parse a comma separated list of integers between lowest and highest. If
+// negative values are allowed, values between -highest and -lowest are also
+// accepted.
+func parseRRuleInts(value string, lowest int, highest int,
+	negative bool) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if abs < lowest || abs > highest {
+			return nil, fmt.Errorf("%d is out of range", n)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// Parse a comma separated list of days of the week, each with an optional
+// ordinal, e.g. MO,2TU,-1FR.
+func parseRRuleWeekdays(value string) ([]rruleWeekday, error) {
+	var weekdays []rruleWeekday
+	for _, field := range strings.Split(value, ",") {
+		if len(field) < 2 {
+			return nil, fmt.Errorf("invalid day %s", field)
+		}
+		weekday, ok := rruleWeekdays[strings.ToUpper(field[len(field)-2:])]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %s", field)
+		}
+
+		n := 0
+		if ordinal := field[:len(field)-2]; ordinal != "" {
+			var err error
+			n, err = strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid day %s", field)
+			}
+		}
+		weekdays = append(weekdays, rruleWeekday{weekday: weekday, n: n})
+	}
+	return weekdays, nil
+}
+
+// Return the content lines of the recurrence set, with DTSTART.
+func (r *recurrenceSet) withStart(lines []string) []string {
+	for _, line := range lines {
+		name, _, _, err := parseICalContentLine(line)
+		if err == nil && name == "DTSTART" {
+			return lines
+		}
+	}
+
+	dtstart := "DTSTART;TZID=" + r.dtstart.Location().String() + ":" +
+		r.dtstart.Format(icalDateTimeLayout)
+	if r.dtstart.Location() == time.UTC {
+		dtstart = "DTSTART:" + r.dtstart.Format(icalUTCDateTimeLayout)
+	}
+	return append([]string{dtstart}, lines...)
+}
+
+// Return the first occurrence of the recurrence set after the specified
+// instant. Returns false if the recurrence has no further occurrences, having
+// reached its COUNT or UNTIL.
+func (r *recurrenceSet) next(after time.Time) (time.Time, bool) {
+	rule := r.rule
+
+	// Occurrences before the specified instant only need to be walked through
+	// when counting them towards the COUNT of the rule.
+	period := 0
+	if rule.count == 0 {
+		period = rule.periodsBefore(r.dtstart, after)
+	}
+
+	count := 0
+	for empty := 0; empty < maxRRuleEmptyPeriods; period++ {
+		occurrences := rule.occurrences(r.dtstart, period)
+		if len(occurrences) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, occurrence := range occurrences {
+			if occurrence.Before(r.dtstart) {
+				continue
+			}
+			if !rule.until.IsZero() && occurrence.After(rule.until) {
+				return time.Time{}, false
+			}
+			count++
+			if rule.count != 0 && count > rule.count {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) && !r.isExcluded(occurrence) {
+				return occurrence, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Check if the occurrence is excluded from the recurrence set by an EXDATE.
+func (r *recurrenceSet) isExcluded(occurrence time.Time) bool {
+	for _, exdate := range r.exdates {
+		if exdate.Equal(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the number of whole periods of the rule, starting from DTSTART, that
+// end before the specified instant.
+func (rule *recurrenceRule) periodsBefore(dtstart time.Time,
+	t time.Time) int {
+	t = t.In(dtstart.Location())
+
+	var elapsed int
+	switch rule.freq {
+	case rruleYearly:
+		elapsed = t.Year() - dtstart.Year()
+	case rruleMonthly:
+		elapsed = (t.Year()-dtstart.Year())*12 +
+			int(t.Month()) - int(dtstart.Month())
+	case rruleWeekly:
+		elapsed = daysBetween(rule.weekOf(dtstart), rule.weekOf(t)) /
+			daysPerWeek
+	case rruleDaily:
+		elapsed = daysBetween(dtstart, t)
+	case rruleHourly:
+		elapsed = int(t.Sub(rule.periodStart(dtstart, 0)) / time.Hour)
+	case rruleMinutely:
+		elapsed = int(t.Sub(rule.periodStart(dtstart, 0)) / time.Minute)
+	case rruleSecondly:
+		elapsed = int(t.Sub(dtstart) / time.Second)
+	}
+
+	periods := elapsed/rule.interval - 1
+	if periods < 0 {
+		return 0
+	}
+	return periods
+}
+
+// Return the start of the specified period of the rule, starting from the
+// period containing DTSTART. Periods of a day or longer start at midnight.
+func (rule *recurrenceRule) periodStart(dtstart time.Time, period int) time.Time {
+	n := period * rule.interval
+	day := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0,
+		0, dtstart.Location())
+
+	switch rule.freq {
+	case rruleYearly:
+		return time.Date(dtstart.Year()+n, time.January, 1, 0, 0, 0, 0,
+			dtstart.Location())
+	case rruleMonthly:
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(n), 1,
+			0, 0, 0, 0, dtstart.Location())
+	case rruleWeekly:
+		return rule.weekOf(dtstart).AddDate(0, 0, daysPerWeek*n)
+	case rruleDaily:
+		return day.AddDate(0, 0, n)
+	case rruleHourly:
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(),
+			dtstart.Hour(), 0, 0, 0, dtstart.Location()).
+			Add(time.Duration(n) * time.Hour)
+	case rruleMinutely:
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(),
+			dtstart.Hour(), dtstart.Minute(), 0, 0, dtstart.Location()).
+			Add(time.Duration(n) * time.Minute)
+	}
+	return dtstart.Add(time.Duration(n) * time.Second)
+}
+
+// Return midnight at the start of the week, as per WKST, of the specified
+// instant.
+func (rule *recurrenceRule) weekOf(t time.Time) time.Time {
+	offset := (int(t.Weekday()) - int(rule.weekStart) + daysPerWeek) %
+		daysPerWeek
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0,
+		t.Location())
+}
+
+// Return the sorted occurrences of the rule within the specified period,
+// including any before DTSTART.
+func (rule *recurrenceRule) occurrences(dtstart time.Time,
+	period int) []time.Time {
+	start := rule.periodStart(dtstart, period)
+
+	var occurrences []time.Time
+	switch rule.freq {
+	case rruleHourly, rruleMinutely, rruleSecondly:
+		if !rule.matchesDay(start) || !matchesAny(rule.byHour, start.Hour()) {
+			return nil
+		}
+		minutes := rule.byMinute
+		if rule.freq != rruleHourly {
+			if !matchesAny(minutes, start.Minute()) {
+				return nil
+			}
+			minutes = []int{start.Minute()}
+		}
+		seconds := rule.bySecond
+		if rule.freq == rruleSecondly {
+			if !matchesAny(seconds, start.Second()) {
+				return nil
+			}
+			seconds = []int{start.Second()}
+		}
+		occurrences = rule.atTimes(dtstart, []time.Time{start},
+			[]int{start.Hour()}, minutes, seconds)
+
+	default:
+		occurrences = rule.atTimes(dtstart, rule.days(dtstart, start),
+			rule.byHour, rule.byMinute, rule.bySecond)
+	}
+
+	return rule.selectSetPositions(sortOccurrences(occurrences))
+}
+
+// Return the days within the period of a daily, weekly, monthly or yearly
+// rule starting at the specified time, on which the rule occurs.
+func (rule *recurrenceRule) days(dtstart time.Time,
+	start time.Time) []time.Time {
+	var days []time.Time
+
+	switch rule.freq {
+	case rruleDaily:
+		days = []time.Time{start}
+
+	case rruleWeekly:
+		if len(rule.byDay) != 0 {
+			days = rule.expandWeekdays(start, start.AddDate(0, 0,
+				daysPerWeek-1))
+			break
+		}
+		offset := (int(dtstart.Weekday()) - int(start.Weekday()) +
+			daysPerWeek) % daysPerWeek
+		days = []time.Time{start.AddDate(0, 0, offset)}
+
+	case rruleMonthly:
+		days = rule.monthDays(dtstart, start)
+
+	case rruleYearly:
+		months := rule.byMonth
+		if len(months) == 0 {
+			if len(rule.byMonthDay) == 0 && len(rule.byDay) == 0 {
+				months = []int{int(dtstart.Month())}
+			} else if len(rule.byMonthDay) == 0 {
+				// Days of the week without months are expanded within the
+				// whole year.
+				return rule.filterDays(rule.expandWeekdays(start,
+					start.AddDate(1, 0, -1)))
+			} else {
+				months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+		}
+		for _, month := range months {
+			days = append(days, rule.monthDays(dtstart,
+				time.Date(start.Year(), time.Month(month), 1, 0, 0, 0, 0,
+					start.Location()))...)
+		}
+	}
+	return rule.filterDays(days)
+}
+
+// Return the days of the rule within the month starting at the specified day.
+func (rule *recurrenceRule) monthDays(dtstart time.Time,
+	month time.Time) []time.Time {
+	lastDay := lastDayOfMonth(month)
+
+	switch {
+	case len(rule.byMonthDay) != 0:
+		var days []time.Time
+		for _, day := range rule.byMonthDay {
+			if day < 0 {
+				day = lastDay + day + 1
+			}
+			if day >= 1 && day <= lastDay {
+				days = append(days, month.AddDate(0, 0, day-1))
+			}
+		}
+		return days
+
+	case len(rule.byDay) != 0:
+		return rule.expandWeekdays(month, month.AddDate(0, 0, lastDay-1))
+
+	case dtstart.Day() <= lastDay:
+		// Months without the day of the month of DTSTART are skipped.
+		return []time.Time{month.AddDate(0, 0, dtstart.Day()-1)}
+	}
+	return nil
+}
+
+// Return the days between first and last, inclusive, which match the days of
+// the week of the rule. Ordinals are relative to the range of days.
+func (rule *recurrenceRule) expandWeekdays(first time.Time,
+	last time.Time) []time.Time {
+	var days []time.Time
+	for _, weekday := range rule.byDay {
+		var matches []time.Time
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == weekday.weekday {
+				matches = append(matches, day)
+			}
+		}
+
+		switch {
+		case weekday.n == 0:
+			days = append(days, matches...)
+		case weekday.n > 0 && weekday.n <= len(matches):
+			days = append(days, matches[weekday.n-1])
+		case weekday.n < 0 && -weekday.n <= len(matches):
+			days = append(days, matches[len(matches)+weekday.n])
+		}
+	}
+	return days
+}
+
+// Return the days which are not excluded by the rule parts limiting the days
+// on which the rule occurs.
+func (rule *recurrenceRule) filterDays(days []time.Time) []time.Time {
+	var filtered []time.Time
+	for _, day := range days {
+		if rule.matchesDay(day) {
+			filtered = append(filtered, day)
+		}
+	}
+	return filtered
+}
+
+// Check if the day of the specified instant matches the months, days of the
+// month and days of the week of the rule.
+func (rule *recurrenceRule) matchesDay(t time.Time) bool {
+	if !matchesAny(rule.byMonth, int(t.Month())) {
+		return false
+	}
+
+	if len(rule.byMonthDay) != 0 {
+		lastDay := lastDayOfMonth(t)
+		matched := false
+		for _, day := range rule.byMonthDay {
+			if day == t.Day() || (day < 0 && lastDay+day+1 == t.Day()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(rule.byDay) != 0 {
+		for _, weekday := range rule.byDay {
+			if weekday.weekday == t.Weekday() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Return the occurrences at each combination of the specified hours, minutes
+// and seconds on the specified days. Hours, minutes and seconds which are not
+// specified are taken from DTSTART.
+func (rule *recurrenceRule) atTimes(dtstart time.Time, days []time.Time,
+	hours []int, minutes []int, seconds []int) []time.Time {
+	if len(hours) == 0 {
+		hours = []int{dtstart.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{dtstart.Minute()}
+	}
+	if len(seconds) == 0 {
+		seconds = []int{dtstart.Second()}
+	}
+
+	var occurrences []time.Time
+	for _, day := range days {
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				for _, second := range seconds {
+					occurrences = append(occurrences, wallClockTime(
+						day.Year(), day.Month(), day.Day(), hour, minute,
+						second, 0, day.Location()))
+				}
+			}
+		}
+	}
+	return occurrences
+}
+
+// Apply the BYSETPOS rule part to the sorted occurrences within a period.
+func (rule *recurrenceRule) selectSetPositions(
+	occurrences []time.Time) []time.Time {
+	if len(rule.bySetPos) == 0 {
+		return occurrences
+	}
+
+	var selected []time.Time
+	for _, pos := range rule.bySetPos {
+		index := pos - 1
+		if pos < 0 {
+			index = len(occurrences) + pos
+		}
+		if index >= 0 && index < len(occurrences) {
+			selected = append(selected, occurrences[index])
+		}
+	}
+	return sortOccurrences(selected)
+}
+
+// Sort the occurrences and remove any duplicates.
+func sortOccurrences(occurrences []time.Time) []time.Time {
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Before(occurrences[j])
+	})
+
+	unique := occurrences[:0]
+	for i, occurrence := range occurrences {
+		if i == 0 || !occurrence.Equal(occurrences[i-1]) {
+			unique = append(unique, occurrence)
+		}
+	}
+	return unique
+}
+
+// Check if the value is one of the specified values. An empty list of values
+// matches any value.
+func matchesAny(values []int, value int) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the number of calendar days from the day of start to the day of end.
+func daysBetween(start time.Time, end time.Time) int {
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0,
+		time.UTC)
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0,
+		time.UTC)
+	return int(endDay.Sub(startDay) / (24 * time.Hour))
+}
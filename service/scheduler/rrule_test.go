@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+// Return the first occurrences of the recurrence set after the specified
+// instant, up to the specified number of occurrences.
+func rruleOccurrences(t *testing.T, spec string, after time.Time,
+	count int) []time.Time {
+	rrule, err := parseRecurrenceSet(strings.Fields(spec), time.UTC, after)
+	if err != nil {
+		t.Fatalf("%q: failed to parse recurrence rule: %v\n", spec, err)
+	}
+
+	var occurrences []time.Time
+	for len(occurrences) < count {
+		next, ok := rrule.next(after)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+		after = next
+	}
+	return occurrences
+}
+
+func checkOccurrences(t *testing.T, spec string, occurrences []time.Time,
+	expected ...string) {
+	if len(occurrences) != len(expected) {
+		t.Errorf("%q: expected %d occurrences, got %v\n", spec, len(expected),
+			occurrences)
+		return
+	}
+	for i, occurrence := range occurrences {
+		if occurrence.UTC().Format(time.RFC3339) != expected[i] {
+			t.Errorf("%q: expected occurrence %d at %s, got %v\n", spec, i,
+				expected[i], occurrence)
+		}
+	}
+}
+
+func TestRRule_SecondTuesday(t *testing.T) {
+	spec := "DTSTART:20240101T090000Z RRULE:FREQ=MONTHLY;BYDAY=2TU"
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	checkOccurrences(t, spec, rruleOccurrences(t, spec, start, 3),
+		"2024-01-09T09:00:00Z", "2024-02-13T09:00:00Z", "2024-03-12T09:00:00Z")
+}
+
+func TestRRule_LastWeekdayOfMonth(t *testing.T) {
+	spec := "DTSTART:20240101T220000Z " +
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	checkOccurrences(t, spec, rruleOccurrences(t, spec, start, 4),
+		"2024-01-31T22:00:00Z", "2024-02-29T22:00:00Z", "2024-03-29T22:00:00Z",
+		"2024-04-30T22:00:00Z")
+}
+
+func TestRRule_CountAndExdate(t *testing.T) {
+	// EXDATE removes an occurrence, but it still counts towards COUNT.
+	spec := "DTSTART:20240301T080000Z RRULE:FREQ=DAILY;COUNT=4 " +
+		"EXDATE:20240302T080000Z"
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	checkOccurrences(t, spec, rruleOccurrences(t, spec, start, 10),
+		"2024-03-01T08:00:00Z", "2024-03-03T08:00:00Z", "2024-03-04T08:00:00Z")
+}
+
+func TestRRule_Until(t *testing.T) {
+	spec := "DTSTART:20240304T120000Z " +
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20240321"
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	checkOccurrences(t, spec, rruleOccurrences(t, spec, start, 10),
+		"2024-03-04T12:00:00Z", "2024-03-07T12:00:00Z", "2024-03-18T12:00:00Z",
+		"2024-03-21T12:00:00Z")
+}
+
+func TestRRule_TimezoneAndSkipAhead(t *testing.T) {
+	// Occurrences follow the wall clock of the TZID across a DST transition.
+	spec := "DTSTART;TZID=Europe/Paris:20200101T090000 " +
+		"RRULE:FREQ=YEARLY;BYMONTH=3,4;BYMONTHDAY=1"
+	start := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	checkOccurrences(t, spec, rruleOccurrences(t, spec, start, 3),
+		"2024-03-01T08:00:00Z", "2024-04-01T07:00:00Z", "2025-03-01T08:00:00Z")
+}
+
+func TestRRule_ParseErrors(t *testing.T) {
+	tests := []struct {
+		schedule string
+		token    string
+	}{
+		{"rrule RRULE:FREQ=FORTNIGHTLY", "RRULE:FREQ=FORTNIGHTLY"},
+		{"rrule DTSTART:2024 RRULE:FREQ=DAILY", "DTSTART:2024"},
+		{"rrule RRULE:FREQ=DAILY;BYDAY=2MO", "RRULE:FREQ=DAILY;BYDAY=2MO"},
+		{"rrule RRULE:FREQ=DAILY;COUNT=2;UNTIL=20240101",
+			"RRULE:FREQ=DAILY;COUNT=2;UNTIL=20240101"},
+		{"rrule RRULE:FREQ=DAILY RDATE:20240101", "RDATE:20240101"},
+		{"rrule DTSTART:20240101T000000Z", ""},
+	}
+
+	for _, test := range tests {
+		_, err := parseSchedule(test.schedule, time.UTC)
+
+		var scheduleErr *ScheduleError
+		if !errors.As(err, &scheduleErr) ||
+			!errors.Is(err, ErrRRuleParseFailure) ||
+			scheduleErr.Token != test.token {
+			t.Errorf("%q: unexpected error %v\n", test.schedule, err)
+		}
+	}
+
+	// A recurrence rule that has already ended cannot be scheduled.
+	_, err := parseSchedule(
+		"rrule DTSTART:20200101T000000Z RRULE:FREQ=DAILY;COUNT=3", time.UTC)
+	if err != ErrRRuleNoRuns {
+		t.Errorf("Expected a recurrence rule with no runs error, got %v\n", err)
+	}
+}
+
+func TestParseSchedule_RRule(t *testing.T) {
+	task := parseTestSchedule(t,
+		"rrule RRULE:FREQ=WEEKLY;BYDAY=TU;BYHOUR=3;BYMINUTE=0;BYSECOND=0 "+
+			"for 5 times")
+	if task.Unit != common.RRule || task.MaxRuns != 5 ||
+		!strings.HasPrefix(task.RRuleSpec, "DTSTART:") {
+		t.Errorf("Unexpected recurrence rule schedule %+v\n", task)
+	}
+
+	// The stored recurrence set drives the computation of subsequent runs.
+	nextRun := firstRunTime(task, time.Now(), time.UTC)
+	if nextRun.Weekday() != time.Tuesday || nextRun.Hour() != 3 {
+		t.Errorf("Unexpected first run %v\n", nextRun)
+	}
+	nextRun, ok := nextRunTime(task, nextRun, time.UTC)
+	if !ok || nextRun.Weekday() != time.Tuesday {
+		t.Errorf("Unexpected next run %v (recurring: %v)\n", nextRun, ok)
+	}
+
+	_, ok = nextRunTime(&db.Task{Unit: common.RRule, RRuleSpec: "garbage"},
+		nextRun, time.UTC)
+	if ok {
+		t.Errorf("Expected no next run for an invalid recurrence rule\n")
+	}
+}
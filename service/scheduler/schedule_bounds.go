@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"go.uber.org/zap"
+)
+
+const (
+	// Maximum number of remaining runs counted for tasks whose schedule does
+	// not have a fixed period.
+	maxRemainingRunCount = 1000
+)
+
+// Parse the end time of a task specified in a request, as an RFC 3339 time.
+// Returns a zero time if no end time is specified.
+func parseEndAt(endAt string) (time.Time, error) {
+	if endAt == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, endAt)
+	if err != nil || !t.After(time.Now()) {
+		return time.Time{}, ErrInvalidEndAt
+	}
+	return t, nil
+}
+
+// ScheduleBounds - specifies the time after which the task is no longer run,
+// as an RFC 3339 time, and the maximum number of runs of the task. These apply
+// in addition to any bounds in the schedule of the task, so that the earlier
+// end time and the smaller number of runs take effect.
+func (s *ScheduledTask) ScheduleBounds(endAt string,
+	maxRuns int32) *ScheduledTask {
+	t, err := parseEndAt(endAt)
+	if err != nil {
+		schedLogger.Error("Invalid end time specified!",
+			zap.String("Specified end time", endAt),
+		)
+		s.error = wrapOrError(s.error, err)
+		return s
+	}
+	if maxRuns < 0 {
+		schedLogger.Error("Invalid maximum number of runs specified!",
+			zap.Int32("Specified maximum runs", maxRuns),
+		)
+		s.error = wrapOrError(s.error, ErrInvalidMaxRuns)
+		return s
+	}
+
+	if !t.IsZero() &&
+		(s.TaskInfo.EndAt.IsZero() || t.Before(s.TaskInfo.EndAt)) {
+		s.TaskInfo.EndAt = t
+	}
+	if maxRuns > 0 &&
+		(s.TaskInfo.MaxRuns <= 0 || int(maxRuns) < s.TaskInfo.MaxRuns) {
+		s.TaskInfo.MaxRuns = int(maxRuns)
+	}
+	return s
+}
+
+// RemainingRuns - returns the number of runs of the task which remain within
+// the bounds of its schedule, including its next scheduled run. Returns false
+// if the schedule of the task is not bounded. Runs of tasks whose schedule
+// does not have a fixed period are counted up to a maximum of 1000.
+func RemainingRuns(task *db.Task) (int, bool) {
+	if !task.HasBoundedSchedule() {
+		return 0, false
+	}
+	if task.Status == db.TaskStatusCancelled.String() ||
+		task.IsScheduleEnded() || !hasPendingRun(task) {
+		return 0, true
+	}
+
+	maxRemaining := -1
+	if task.MaxRuns > 0 {
+		maxRemaining = task.MaxRuns - task.RunCount
+		if maxRemaining <= 0 {
+			return 0, true
+		}
+	}
+
+	// Runs of fixed period schedules up to the end time of the task are
+	// counted directly.
+	period, ok := fixedPeriod(task)
+	if ok && !task.EndAt.IsZero() {
+		remaining := 0
+		if !task.NextRun.After(task.EndAt) {
+			remaining = int(task.EndAt.Sub(task.NextRun)/period) + 1
+		}
+		if maxRemaining >= 0 && maxRemaining < remaining {
+			remaining = maxRemaining
+		}
+		return remaining, true
+	}
+	if task.EndAt.IsZero() {
+		return maxRemaining, true
+	}
+
+	// Runs are counted using the times in the schedule of the task, which the
+	// runs of tasks with a jitter window are offset from.
+	loc := taskLocation(task)
+	offset := jitterOffset(task)
+	runTime := task.NextRun.Add(-offset)
+	remaining := 0
+	for remaining < maxRemainingRunCount &&
+		withinScheduleBounds(task, runTime.Add(offset),
+			task.RunCount+remaining) {
+		remaining++
+
+		nextRun, ok := nextRunTime(task, runTime, loc)
+		if !ok || !nextRun.After(runTime) {
+			break
+		}
+		runTime = nextRun
+	}
+	return remaining, true
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestScheduleBounds(t *testing.T) {
+	endAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	s := &ScheduledTask{location: time.UTC, TaskInfo: &db.Task{}}
+	s.ParseSchedule("every 1 hour for 10 times").
+		ScheduleBounds(endAt.Format(time.RFC3339), 5)
+	if s.error != nil || !s.TaskInfo.EndAt.Equal(endAt) ||
+		s.TaskInfo.MaxRuns != 5 {
+		t.Errorf("Unexpected bounds %v, %d (%v)\n", s.TaskInfo.EndAt,
+			s.TaskInfo.MaxRuns, s.error)
+	}
+
+	// The tighter of the bounds in the schedule and the request applies.
+	s = &ScheduledTask{location: time.UTC, TaskInfo: &db.Task{}}
+	s.ParseSchedule("every 1 hour for 3 times").ScheduleBounds("", 5)
+	if s.error != nil || s.TaskInfo.MaxRuns != 3 {
+		t.Errorf("Expected the maximum runs of the schedule, got %d (%v)\n",
+			s.TaskInfo.MaxRuns, s.error)
+	}
+
+	tests := []struct {
+		endAt   string
+		maxRuns int32
+		err     error
+	}{
+		{"tomorrow", 0, ErrInvalidEndAt},
+		{"2020-01-01T00:00:00Z", 0, ErrInvalidEndAt},
+		{"", -1, ErrInvalidMaxRuns},
+	}
+	for _, test := range tests {
+		s = &ScheduledTask{location: time.UTC, TaskInfo: &db.Task{}}
+		s.ParseSchedule("every 1 hour").ScheduleBounds(test.endAt, test.maxRuns)
+		if s.error != test.err {
+			t.Errorf("%q, %d: expected error %v, got %v\n", test.endAt,
+				test.maxRuns, test.err, s.error)
+		}
+	}
+}
+
+func TestRemainingRuns(t *testing.T) {
+	nextRun := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	endAt := nextRun.Add(10 * time.Hour)
+	cronSpec := "CRON_TZ=UTC 0 12 * * *"
+
+	tests := []struct {
+		name      string
+		task      *db.Task
+		remaining int
+		bounded   bool
+	}{
+		{"Unbounded",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: nextRun},
+			0, false},
+		{"MaxRuns",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: nextRun,
+				MaxRuns: 5, RunCount: 2},
+			3, true},
+		{"FixedPeriodEndAt",
+			&db.Task{Unit: common.Hours, Interval: 2, NextRun: nextRun,
+				EndAt: endAt},
+			6, true},
+		{"FixedPeriodEndAtAndMaxRuns",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: nextRun,
+				EndAt: endAt, MaxRuns: 4},
+			4, true},
+		{"CronEndAt",
+			&db.Task{Unit: common.Crontab, CronSpec: cronSpec,
+				NextRun: nextRun, EndAt: nextRun.Add(72 * time.Hour)},
+			4, true},
+		{"ScheduleEnded",
+			&db.Task{Unit: common.Hours, Interval: 1, MaxRuns: 5,
+				RunCount: 5},
+			0, true},
+		{"Cancelled",
+			&db.Task{Unit: common.Hours, Interval: 1, NextRun: nextRun,
+				MaxRuns: 5, Status: db.TaskStatusCancelled.String()},
+			0, true},
+	}
+
+	for _, test := range tests {
+		remaining, bounded := RemainingRuns(test.task)
+		if remaining != test.remaining || bounded != test.bounded {
+			t.Errorf("%s: expected %d (%v), got %d (%v)\n", test.name,
+				test.remaining, test.bounded, remaining, bounded)
+		}
+	}
+}
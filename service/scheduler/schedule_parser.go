@@ -0,0 +1,532 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"go.uber.org/zap"
+)
+
+// Keywords introducing the bounds of a schedule.
+const (
+	scheduleBoundStarting = "starting"
+	scheduleBoundUntil    = "until"
+	scheduleBoundFor      = "for"
+	scheduleBoundWithin   = "within"
+)
+
+// SupportedScheduleForms - the forms of schedule strings supported by the
+// scheduler. These are returned with errors encountered while parsing a
+// schedule string.
+var SupportedScheduleForms = []string{
+	"now",
+	"every <n> milliseconds|seconds|minutes|hours",
+	"every <duration>, e.g. every 90s or every 1h30m",
+	"every [<n>] days [at <time>[;<time>...]]",
+	"every [<n>] weeks [<weekday>[,<weekday>...]] [at <time>[;<time>...]]",
+	"every [<n>] <weekday>[,<weekday>...] [at <time>[;<time>...]]",
+	"every [<n>] monthdays <day>[,<day>...] [at <time>[;<time>...]]",
+	"at <time>[;<time>...], where <time> is HH:MM or HH:MM:SS",
+	"cron [CRON_TZ=<timezone>] <5 or 6 field cron expression or @descriptor>",
+	"rrule [DTSTART[;TZID=<timezone>]:<time>] RRULE:<RFC 5545 rule> " +
+		"[EXDATE[;TZID=<timezone>]:<time>[,<time>...]]",
+	"any of the above except now, followed by starting <RFC3339 time>, " +
+		"until <RFC3339 time>, for <n> times and/or within <duration>",
+}
+
+// Weekdays which may be specified in schedule strings.
+var scheduleWeekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// A whitespace separated token of a schedule string, along with its offset
+// from the start of the schedule string.
+type scheduleToken struct {
+	text     string
+	position int
+}
+
+// Split the specified schedule string into whitespace separated tokens.
+func tokenizeSchedule(scheduleStr string) []scheduleToken {
+	var tokens []scheduleToken
+
+	start := -1
+	for i, r := range scheduleStr {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, scheduleToken{scheduleStr[start:i], start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, scheduleToken{scheduleStr[start:], start})
+	}
+	return tokens
+}
+
+// Parses a tokenized schedule string and applies the schedule to the task.
+type scheduleParser struct {
+	s        *ScheduledTask
+	schedule string
+	tokens   []scheduleToken
+	next     int
+}
+
+// ParseSchedule - parses the specified schedule string and applies the
+// schedule to the task. Sample schedule strings:
+// - every 2h
+// - every 1 day at 10:00;14:00
+// - every 1 week monday at 09:00 until 2025-01-01T00:00:00Z
+// - every 1 monthdays 1,15 for 12 times
+// - cron */5 * * * * *
+// - rrule DTSTART:20240109T090000Z RRULE:FREQ=MONTHLY;BYDAY=2TU;COUNT=12
+//
+// See SupportedScheduleForms for the complete list of supported forms. Errors
+// encountered while parsing the schedule are reported as a ScheduleError
+// identifying the offending token of the schedule string.
+func (s *ScheduledTask) ParseSchedule(scheduleStr string) *ScheduledTask {
+
+	// If the schedule is not specified, default to scheduling the task
+	// immediately.
+	if (strings.TrimSpace(scheduleStr) == "") ||
+		(strings.ToLower(strings.TrimSpace(scheduleStr)) ==
+			common.SchedulingFrequencyNow) {
+		s.TaskInfo.Unit = common.Once
+		s.TaskInfo.StartAt = time.Now()
+		s.TaskInfo.StartImmediately = true
+		return s.Now()
+	}
+
+	// Errors encountered while building the task before the schedule was
+	// parsed are combined with any error parsing the schedule.
+	prevErr := s.error
+	s.error = nil
+
+	p := &scheduleParser{
+		s:        s,
+		schedule: scheduleStr,
+		tokens:   tokenizeSchedule(scheduleStr),
+	}
+	err := p.parse()
+	if err != nil {
+		schedLogger.Error("Invalid task schedule specified",
+			zap.String("Schedule string", scheduleStr),
+			zap.Error(err),
+		)
+	}
+
+	s.error = prevErr
+	if err != nil {
+		s.error = wrapOrError(prevErr, err)
+	}
+	return s
+}
+
+// Parse the schedule: a schedule type (every, at, cron or rrule) followed by the
+// optional bounds of the schedule.
+func (p *scheduleParser) parse() error {
+	token := p.advance()
+
+	var err error
+	switch strings.ToLower(token.text) {
+	case common.SchedulingFrequencyEvery:
+		err = p.parseEvery()
+
+	case common.SchedulingFrequencyAt:
+		// Tasks scheduled at times of day without an interval run daily.
+		p.s.TaskInfo.Interval = 1
+		p.s.setSchedulingUnit(common.Days)
+		err = p.parseTimesOfDay()
+
+	case common.SchedulingFrequencyCron:
+		err = p.parseCron()
+
+	case common.SchedulingFrequencyRRule:
+		err = p.parseRRule()
+
+	default:
+		err = newScheduleError(token, ErrInvalidScheduleType)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = p.parseBounds()
+	if err != nil {
+		return err
+	}
+
+	if token, ok := p.peek(); ok {
+		return newScheduleError(token, ErrUnexpectedScheduleToken)
+	}
+	return nil
+}
+
+// Parse a periodic schedule following the every keyword: an optional interval
+// followed by a scheduling unit, days of the week or days of the month, and
+// the times of day at which the task runs.
+func (p *scheduleParser) parseEvery() error {
+	token, ok := p.peek()
+	if !ok {
+		return newScheduleError(p.endToken(), ErrMissingSchedulingUnit)
+	}
+
+	// The interval is either a number of scheduling units or a duration
+	// string. If the interval is omitted, it defaults to 1.
+	hasInterval := false
+	interval, err := strconv.Atoi(token.text)
+	switch {
+	case err == nil:
+		p.advance()
+		hasInterval = true
+		err = p.apply(token, func(s *ScheduledTask) *ScheduledTask {
+			return s.Every(interval)
+		})
+		if err != nil {
+			return err
+		}
+
+	case !p.isKeyword(token):
+		p.advance()
+		return p.apply(token, func(s *ScheduledTask) *ScheduledTask {
+			return s.Every(token.text)
+		})
+
+	default:
+		p.s.TaskInfo.Interval = 1
+	}
+
+	token, ok = p.peek()
+	if !ok || p.isBound(token) {
+		if hasInterval {
+			return newScheduleError(p.tokenOrEnd(), ErrMissingSchedulingUnit)
+		}
+		return newScheduleError(p.tokenOrEnd(), ErrInvalidSchedulingUnit)
+	}
+
+	unit := strings.ToLower(token.text)
+	switch {
+	case unit == "monthdays":
+		p.advance()
+		days, ok := p.peek()
+		if !ok || p.isBound(days) {
+			return newScheduleError(p.tokenOrEnd(), ErrInvalidDayOfMonthEntry)
+		}
+		p.advance()
+		err = p.apply(days, func(s *ScheduledTask) *ScheduledTask {
+			daysOfMonth := s.ParseDaysOfMonth(days.text)
+			if s.error != nil {
+				return s
+			}
+			return s.Month(daysOfMonth...)
+		})
+
+	case p.isWeekdays(token):
+		err = p.parseWeekdays()
+
+	default:
+		schedulingUnitFn, ok := schedulingUnitLookupTable[unit]
+		if !ok {
+			return newScheduleError(token, ErrInvalidSchedulingUnit)
+		}
+		p.advance()
+		err = p.apply(token, schedulingUnitFn)
+		if err == nil && p.s.TaskInfo.Unit == 0 {
+			// Tasks scheduled at midday run daily.
+			p.s.setSchedulingUnit(common.Days)
+		}
+		if err == nil && p.s.TaskInfo.Unit == common.Weeks {
+			if weekdays, ok := p.peek(); ok && p.isWeekdays(weekdays) {
+				err = p.parseWeekdays()
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Check for the times of day at which the task runs.
+	token, ok = p.peek()
+	if ok && strings.ToLower(token.text) == common.SchedulingFrequencyAt {
+		switch p.s.TaskInfo.Unit {
+		case common.Days, common.Weeks, common.Months:
+			p.advance()
+			return p.parseTimesOfDay()
+		}
+		return newScheduleError(token, ErrAtTimeNotSupported)
+	}
+	return nil
+}
+
+// Parse the days of the week on which the task runs, specified either as
+// separate tokens or as comma separated lists.
+func (p *scheduleParser) parseWeekdays() error {
+	for {
+		token, ok := p.peek()
+		if !ok || !p.isWeekdays(token) {
+			return nil
+		}
+		p.advance()
+
+		for _, name := range strings.Split(strings.ToLower(token.text), ",") {
+			weekday := scheduleWeekdays[name]
+			err := p.apply(token, func(s *ScheduledTask) *ScheduledTask {
+				return s.Weekday(weekday)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Parse the semicolon separated times of day at which the task runs.
+func (p *scheduleParser) parseTimesOfDay() error {
+	token, ok := p.peek()
+	if !ok || p.isBound(token) {
+		return newScheduleError(p.tokenOrEnd(), ErrUnsupportedTimeFormat)
+	}
+	p.advance()
+
+	return p.apply(token, func(s *ScheduledTask) *ScheduledTask {
+		return s.At(token.text)
+	})
+}
+
+// Parse a cron expression. The fields of the cron expression extend up to the
+// bounds of the schedule, or the end of the schedule string. Expressions with
+// six fields include a seconds field.
+func (p *scheduleParser) parseCron() error {
+	var fields []scheduleToken
+	for {
+		token, ok := p.peek()
+		if !ok || p.isBound(token) {
+			break
+		}
+		p.advance()
+		fields = append(fields, token)
+	}
+
+	expression := fields
+	if len(expression) != 0 &&
+		(strings.HasPrefix(expression[0].text, "TZ=") ||
+			strings.HasPrefix(expression[0].text, "CRON_TZ=")) {
+		expression = expression[1:]
+	}
+	if len(expression) == 0 {
+		return newScheduleError(p.tokenOrEnd(), ErrCronParseFailure)
+	}
+
+	withSeconds := false
+	if !strings.HasPrefix(expression[0].text, "@") {
+		switch {
+		case len(expression) < 5:
+			return newScheduleError(p.tokenOrEnd(), ErrCronParseFailure)
+		case len(expression) == 6:
+			withSeconds = true
+		case len(expression) > 6:
+			return newScheduleError(expression[6], ErrCronParseFailure)
+		}
+	}
+
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		texts[i] = field.text
+	}
+	return p.apply(expression[0], func(s *ScheduledTask) *ScheduledTask {
+		return s.Cron(strings.Join(texts, " "), withSeconds)
+	})
+}
+
+// Parse an iCalendar recurrence set. Its content lines extend up to the bounds
+// of the schedule, or the end of the schedule string. Errors are attributed to
+// the offending content line.
+func (p *scheduleParser) parseRRule() error {
+	var lines []scheduleToken
+	for {
+		token, ok := p.peek()
+		if !ok || p.isBound(token) {
+			break
+		}
+		p.advance()
+		lines = append(lines, token)
+	}
+	if len(lines) == 0 {
+		return newScheduleError(p.tokenOrEnd(), ErrRRuleParseFailure)
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	p.s = p.s.RRule(strings.Join(texts, " "))
+	if p.s.error == nil {
+		return nil
+	}
+
+	err := p.s.error
+	p.s.error = nil
+	token := lines[0]
+	var lineErr *rruleLineError
+	if errors.As(err, &lineErr) {
+		token = p.tokenOrEnd()
+		if lineErr.line < len(lines) {
+			token = lines[lineErr.line]
+		}
+	}
+	return newScheduleError(token, err)
+}
+
+// Parse the optional bounds of the schedule - the time at which the task
+// starts, the time after which the task no longer runs and the maximum number
+// of runs of the task. Each bound may be specified once.
+func (p *scheduleParser) parseBounds() error {
+	seen := map[string]bool{}
+
+	for {
+		keyword, ok := p.peek()
+		if !ok || !p.isBound(keyword) {
+			return nil
+		}
+		bound := strings.ToLower(keyword.text)
+		if seen[bound] {
+			return newScheduleError(keyword, ErrInvalidScheduleBound)
+		}
+		seen[bound] = true
+		p.advance()
+
+		value, ok := p.peek()
+		if !ok {
+			return newScheduleError(p.endToken(), ErrInvalidScheduleBound)
+		}
+		p.advance()
+
+		switch bound {
+		case scheduleBoundStarting, scheduleBoundUntil:
+			t, err := time.Parse(time.RFC3339, value.text)
+			if err != nil {
+				return newScheduleError(value, ErrInvalidScheduleBound)
+			}
+			if bound == scheduleBoundStarting {
+				p.s.StartAt(t)
+			} else {
+				p.s.TaskInfo.EndAt = t
+			}
+
+		case scheduleBoundFor:
+			count, err := strconv.Atoi(value.text)
+			if err != nil || count <= 0 {
+				return newScheduleError(value, ErrInvalidScheduleBound)
+			}
+			p.s.TaskInfo.MaxRuns = count
+
+			times, ok := p.peek()
+			if !ok {
+				return newScheduleError(p.endToken(), ErrInvalidScheduleBound)
+			}
+			switch strings.ToLower(times.text) {
+			case "time", "times":
+				p.advance()
+			default:
+				return newScheduleError(times, ErrInvalidScheduleBound)
+			}
+
+		case scheduleBoundWithin:
+			window, err := time.ParseDuration(value.text)
+			if err != nil || window <= 0 {
+				return newScheduleError(value, ErrInvalidScheduleBound)
+			}
+			p.s.TaskInfo.JitterWindow = window
+		}
+	}
+}
+
+// Apply the specified function to the task being scheduled and attribute any
+// error it encounters to the specified token.
+func (p *scheduleParser) apply(token scheduleToken,
+	fn func(*ScheduledTask) *ScheduledTask) error {
+	p.s = fn(p.s)
+	if p.s.error != nil {
+		err := p.s.error
+		p.s.error = nil
+		return newScheduleError(token, err)
+	}
+	return nil
+}
+
+// Return the next token without consuming it.
+func (p *scheduleParser) peek() (scheduleToken, bool) {
+	if p.next >= len(p.tokens) {
+		return scheduleToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+// Consume and return the next token. At the end of the schedule string, an
+// empty token positioned at the end is returned.
+func (p *scheduleParser) advance() scheduleToken {
+	token, ok := p.peek()
+	if !ok {
+		return p.endToken()
+	}
+	p.next++
+	return token
+}
+
+// Return the next token or, at the end of the schedule string, an empty token
+// positioned at the end.
+func (p *scheduleParser) tokenOrEnd() scheduleToken {
+	token, ok := p.peek()
+	if !ok {
+		return p.endToken()
+	}
+	return token
+}
+
+// Return an empty token positioned at the end of the schedule string.
+func (p *scheduleParser) endToken() scheduleToken {
+	return scheduleToken{position: len(p.schedule)}
+}
+
+// Check if the token introduces a bound of the schedule.
+func (p *scheduleParser) isBound(token scheduleToken) bool {
+	switch strings.ToLower(token.text) {
+	case scheduleBoundStarting, scheduleBoundUntil, scheduleBoundFor,
+		scheduleBoundWithin:
+		return true
+	}
+	return false
+}
+
+// Check if the token is a comma separated list of days of the week.
+func (p *scheduleParser) isWeekdays(token scheduleToken) bool {
+	for _, name := range strings.Split(strings.ToLower(token.text), ",") {
+		if _, ok := scheduleWeekdays[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if the token is a keyword of the schedule grammar, rather than an
+// interval following the every keyword.
+func (p *scheduleParser) isKeyword(token scheduleToken) bool {
+	unit := strings.ToLower(token.text)
+	if _, ok := schedulingUnitLookupTable[unit]; ok {
+		return true
+	}
+	return unit == "monthdays" || p.isWeekdays(token) || p.isBound(token)
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func parseTestSchedule(t *testing.T, schedule string) *db.Task {
+	task, err := parseSchedule(schedule, time.UTC)
+	if err != nil {
+		t.Fatalf("%q: failed to parse schedule: %v\n", schedule, err)
+	}
+	return task
+}
+
+func TestParseSchedule_Cron(t *testing.T) {
+	task := parseTestSchedule(t, "cron */5 * * * * *")
+	if task.Unit != common.Crontab || task.CronSpec != "CRON_TZ=UTC */5 * * * * *" ||
+		!task.CronWithSeconds {
+		t.Errorf("Unexpected cron schedule %q (seconds: %v)\n", task.CronSpec,
+			task.CronWithSeconds)
+	}
+
+	task = parseTestSchedule(t, "cron CRON_TZ=Europe/Paris 30 2 * * 1-5")
+	if task.CronSpec != "CRON_TZ=Europe/Paris 30 2 * * 1-5" ||
+		task.CronWithSeconds {
+		t.Errorf("Unexpected cron schedule %q (seconds: %v)\n", task.CronSpec,
+			task.CronWithSeconds)
+	}
+
+	task = parseTestSchedule(t, "cron @daily for 5 times")
+	if task.CronSpec != "CRON_TZ=UTC @daily" || task.MaxRuns != 5 {
+		t.Errorf("Unexpected cron schedule %q (max runs: %d)\n", task.CronSpec,
+			task.MaxRuns)
+	}
+}
+
+func TestParseSchedule_Weekdays(t *testing.T) {
+	task := parseTestSchedule(t, "every 1 week monday at 09:00")
+	if task.Unit != common.Weeks || task.Interval != 1 ||
+		!reflect.DeepEqual(task.ScheduledWeekdays,
+			[]time.Weekday{time.Monday}) ||
+		!reflect.DeepEqual(task.RunAt, []time.Duration{9 * time.Hour}) {
+		t.Errorf("Unexpected weekly schedule %+v\n", task)
+	}
+
+	task = parseTestSchedule(t, "every tuesday,friday at 18:30")
+	if task.Unit != common.Weeks || task.Interval != 1 ||
+		!reflect.DeepEqual(task.ScheduledWeekdays,
+			[]time.Weekday{time.Tuesday, time.Friday}) {
+		t.Errorf("Unexpected weekly schedule %+v\n", task)
+	}
+}
+
+func TestParseSchedule_TimesOfDay(t *testing.T) {
+	task := parseTestSchedule(t, "at 10:00;14:00")
+	if task.Unit != common.Days || task.Interval != 1 ||
+		!reflect.DeepEqual(task.RunAt,
+			[]time.Duration{10 * time.Hour, 14 * time.Hour}) {
+		t.Errorf("Unexpected daily schedule %+v\n", task)
+	}
+}
+
+func TestParseSchedule_Bounds(t *testing.T) {
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	end := start.AddDate(0, 1, 0)
+
+	task := parseTestSchedule(t, "every 2 hours starting "+
+		start.Format(time.RFC3339)+" until "+end.Format(time.RFC3339)+
+		" for 3 times")
+	if !task.StartAt.Equal(start) || !task.EndAt.Equal(end) ||
+		task.MaxRuns != 3 {
+		t.Errorf("Unexpected schedule bounds %v - %v (max runs: %d)\n",
+			task.StartAt, task.EndAt, task.MaxRuns)
+	}
+}
+
+func TestParseSchedule_Errors(t *testing.T) {
+	tests := []struct {
+		schedule string
+		token    string
+		position int
+		err      error
+	}{
+		{"every 2 hours at 10:00", "at", 14, ErrAtTimeNotSupported},
+		{"every", "", 5, ErrMissingSchedulingUnit},
+		{"hourly", "hourly", 0, ErrInvalidScheduleType},
+		{"every 2", "", 7, ErrMissingSchedulingUnit},
+		{"every 2 hours tomorrow", "tomorrow", 14, ErrUnexpectedScheduleToken},
+		{"every 2 hours for 0 times", "0", 18, ErrInvalidScheduleBound},
+		{"every 2 hours for 2 times for 3 times", "for", 26,
+			ErrInvalidScheduleBound},
+		{"every 1 day until tomorrow", "tomorrow", 18, ErrInvalidScheduleBound},
+		{"cron * * * *", "", 12, ErrCronParseFailure},
+		{"cron * * * * * * *", "*", 17, ErrCronParseFailure},
+		{"every 1 day until 2000-01-01T00:00:00Z", "", 0,
+			ErrInvalidScheduleBound},
+	}
+
+	for _, test := range tests {
+		_, err := parseSchedule(test.schedule, time.UTC)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, got %v\n", test.schedule, test.err, err)
+			continue
+		}
+
+		// Schedules which failed to parse are not validated further.
+		if strings.HasPrefix(err.Error(), ErrInvalidInterval.Error()) {
+			t.Errorf("%q: unexpected invalid interval error %v\n",
+				test.schedule, err)
+		}
+
+		var scheduleErr *ScheduleError
+		if errors.As(err, &scheduleErr) &&
+			(scheduleErr.Token != test.token ||
+				scheduleErr.Position != test.position) {
+			t.Errorf("%q: unexpected schedule error %+v\n", test.schedule,
+				scheduleErr)
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/hpinc/krypton-scheduler/service/common"
-	"go.uber.org/zap"
 )
 
 var (
@@ -36,95 +35,11 @@ var (
 	}
 )
 
-// Sample schedule strings:
-// - Every 2h
-// - Every 1 day
-// - Every 1 week
-func (s *ScheduledTask) ParseSchedule(scheduleStr string) *ScheduledTask {
-
-	// If the schedule is not specified, default to scheduling the task
-	// immediately.
-	if (scheduleStr == "") ||
-		(strings.ToLower(scheduleStr) == common.SchedulingFrequencyNow) {
-		s.TaskInfo.Unit = common.Once
-		s.TaskInfo.StartAt = time.Now()
-		s.TaskInfo.StartImmediately = true
-		return s.Now()
-	}
-
-	// Parse the requested task schedule so we can determine how to send it to
-	// the scheduler.
-	parsedSchedule := strings.Split(scheduleStr, " ")
-	if len(parsedSchedule) < 2 {
-		schedLogger.Error("Invalid task schedule specified",
-			zap.String("Schedule string: ", scheduleStr),
-		)
-		s.error = wrapOrError(s.error, ErrInvalidScheduleType)
-		return s
-	}
-
-	switch strings.ToLower(parsedSchedule[0]) {
-
-	// Tasks performed periodically at the specified interval.
-	case common.SchedulingFrequencyEvery:
-		// First try to parse as an integer to figure out if the interval is
-		// being set. If this fails, fall back to treating it as a duration
-		// string.
-		duration, err := strconv.Atoi(parsedSchedule[1])
-		if err == nil {
-			s = s.Every(duration)
-		} else {
-			s = s.Every(parsedSchedule[1])
-		}
-
-		// Determine if the caller specified a scheduling unit along with the
-		// interval.
-		if len(parsedSchedule) > 2 {
-			unit := strings.ToLower(parsedSchedule[2])
-			switch unit {
-			case "monthdays":
-				// Parse the Days of the month specified.
-				if len(parsedSchedule) < 4 {
-					schedLogger.Error("Invalid task schedule specified for days of the month",
-						zap.String("Schedule string", scheduleStr),
-					)
-					s.error = wrapOrError(s.error, ErrInvalidScheduleType)
-					return s
-				}
-				s = s.Month(s.ParseDaysOfMonth(parsedSchedule[3])...)
-
-			default:
-				schedulingUnitFn, ok := schedulingUnitLookupTable[unit]
-				if !ok {
-					schedLogger.Error("Invalid scheduling unit requested!",
-						zap.String("Requested unit", parsedSchedule[2]),
-					)
-					s.error = wrapOrError(s.error, ErrInvalidSchedulingUnit)
-					return s
-				}
-				s = schedulingUnitFn(s)
-			}
-		}
-
-	// Tasks performed at a specified time of day - specified in "HH:MM:SS" or
-	// "HH:MM" or time.Time format.
-	case common.SchedulingFrequencyAt:
-		s = s.At(parsedSchedule[1])
-
-	// Tasks performed at a requested cron schedule time.
-	case common.SchedulingFrequencyCron:
-		s = s.Cron(parsedSchedule[1], true)
-
-	default:
-		s.error = wrapOrError(s.error, ErrInvalidScheduleType)
-	}
-	return s
-}
-
 // setSchedulingUnit sets the type of scheduling unit for the task.
 func (s *ScheduledTask) setSchedulingUnit(unit common.SchedulingUnit) {
 	currentUnit := s.TaskInfo.Unit
-	if currentUnit == common.Duration || currentUnit == common.Crontab {
+	if currentUnit == common.Duration || currentUnit == common.Crontab ||
+		currentUnit == common.RRule {
 		s.error = wrapOrError(s.error, ErrInvalidIntervalUnitsSelection)
 		return
 	}
@@ -53,12 +53,49 @@ func handleTaskResponseMessage(mqttTopic string, message *pb.DeviceMessage) erro
 		return ErrInvalidTenantID
 	}
 
-	// Update the status of the task in the database.
+	// Responses to cancelled tasks are ignored.
+	if foundTask.Status == db.TaskStatusCancelled.String() {
+		schedLogger.Info("Ignoring response from the device for a cancelled task!",
+			zap.String("Task ID", message.TaskId),
+			zap.String("Device ID", claims.Subject),
+		)
+		return nil
+	}
+
+	// Update the status of the task in the database. Failed tasks are retried
+	// as per the retry policy for the task. Paused tasks stay paused and are
+	// not retried, but return to the status reported by the device when they
+	// are resumed.
+	taskStatus := message.TaskStatus
 	switch strings.ToLower(message.TaskStatus) {
 	case "complete", "success":
-		err = db.MarkTaskComplete(foundTask)
+		err = changeTask(foundTask, func(task *db.Task) error {
+			if task.Status != db.TaskStatusPaused.String() {
+				return pausedAsChanged(db.MarkTaskComplete(task))
+			}
+
+			status := db.TaskStatusCompleted
+			if task.IsScheduleEnded() {
+				status = db.TaskStatusScheduleExhausted
+			}
+			return db.SetPausedTaskStatus(task, status)
+		})
 	case "failed", "error":
-		err = db.MarkTaskFailed(foundTask)
+		var retried bool
+		err = changeTask(foundTask, func(task *db.Task) error {
+			if task.Status == db.TaskStatusPaused.String() {
+				retried = false
+				return db.SetPausedTaskStatus(task, db.TaskStatusFailed)
+			}
+
+			var err error
+			retried, err = retryTask(task, message.TaskStatus,
+				db.TaskStatusFailed)
+			return pausedAsChanged(err)
+		})
+		if retried {
+			taskStatus = db.TaskStatusPendingRetry.String()
+		}
 	default:
 		schedLogger.Error("Device message (task response) specified an invalid status!",
 			zap.String("Task ID", message.TaskId),
@@ -74,29 +111,47 @@ func handleTaskResponseMessage(mqttTopic string, message *pb.DeviceMessage) erro
 		)
 	}
 
-	// Determine the appropriate registered service queue topic to which this
-	// message should be dispatched.
-	queueTopic := db.GetServiceQueueTopic(foundTask.ServiceID, mqttTopic)
-	if queueTopic == "" {
-		schedLogger.Error("Cannot determine a service queue topic to dispatch MQTT message!",
-			zap.String("Service ID", claims.ManagementService),
-			zap.String("MQTT topic", mqttTopic),
-		)
-		return ErrInvalidMessageType
-	}
+	// Record the response from the device in the run history of the task.
+	_ = db.EndTaskRun(foundTask, message.TaskStatus, message.MessageId)
 
-	payload, err := proto.Marshal(&pb.DeviceEvent{
+	return publishDeviceEvent(mqttTopic, &pb.DeviceEvent{
 		Version:       1,
 		ServiceId:     claims.ManagementService,
 		DeviceId:      claims.Subject,
 		TaskId:        message.TaskId,
 		ConsignmentId: foundTask.ConsignmentID,
 		TenantId:      claims.TenantID,
-		TaskStatus:    message.TaskStatus,
+		TaskStatus:    taskStatus,
 		MessageId:     message.MessageId,
 		MessageType:   message.MessageType,
 		Payload:       message.Payload,
 	})
+}
+
+// Responses for tasks which were paused while the response was being processed
+// are processed again, so that they are recorded with the paused task.
+func pausedAsChanged(err error) error {
+	if err == db.ErrTaskPaused {
+		return db.ErrTaskStatusChanged
+	}
+	return err
+}
+
+// Publish the specified device event to the queue registered by the service
+// for messages received on the specified MQTT topic.
+func publishDeviceEvent(mqttTopic string, event *pb.DeviceEvent) error {
+	// Determine the appropriate registered service queue topic to which this
+	// message should be dispatched.
+	queueTopic := db.GetServiceQueueTopic(event.ServiceId, mqttTopic)
+	if queueTopic == "" {
+		schedLogger.Error("Cannot determine a service queue topic to dispatch MQTT message!",
+			zap.String("Service ID", event.ServiceId),
+			zap.String("MQTT topic", mqttTopic),
+		)
+		return ErrInvalidMessageType
+	}
+
+	payload, err := proto.Marshal(event)
 	if err != nil {
 		schedLogger.Error("Failed to marshal device message for sending to the service!",
 			zap.Error(err),
@@ -110,12 +165,12 @@ func handleTaskResponseMessage(mqttTopic string, message *pb.DeviceMessage) erro
 
 	// Send the task response message to the corresponding queue for the target
 	// service.
-	err = queuemgr.Provider.SendMessage(claims.ManagementService, queueTopic,
+	err = queuemgr.Provider.SendMessage(event.ServiceId, queueTopic,
 		&b64Payload)
 	if err != nil {
 		schedLogger.Error("Failed to dispatch the task response message to the service!",
-			zap.String("Task ID", message.TaskId),
-			zap.String("Device ID", claims.Subject),
+			zap.String("Task ID", event.TaskId),
+			zap.String("Device ID", event.DeviceId),
 			zap.Error(err),
 		)
 		return err
@@ -3,7 +3,11 @@ package scheduler
 import (
 	"regexp"
 	"sort"
+	"sync"
 	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"go.uber.org/zap"
 )
 
 // regex patterns for supported time formats
@@ -31,6 +35,85 @@ func parseTime(t string) (hour, min, sec int, err error) {
 	return parsedTime.Hour(), parsedTime.Minute(), parsedTime.Second(), nil
 }
 
+// Locations loaded from the timezone database, keyed by the timezone name.
+var locationCache sync.Map
+
+// Load the location with the specified IANA timezone name. If no timezone is
+// specified, UTC is returned. Loaded locations are cached, as computing the
+// next run of a task requires the location of the task.
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locationCache.Load(timezone); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	locationCache.Store(timezone, loc)
+	return loc, nil
+}
+
+// Return the location in which the schedule of the task is interpreted. Tasks
+// without a timezone, or with a timezone that can no longer be loaded, are
+// scheduled in UTC.
+func taskLocation(task *db.Task) *time.Location {
+	loc, err := loadLocation(task.Timezone)
+	if err != nil {
+		schedLogger.Error("Failed to load the timezone of the task!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.String("Timezone", task.Timezone),
+			zap.Error(err),
+		)
+		return time.UTC
+	}
+	return loc
+}
+
+// Return the instant at which the clocks in the specified location show the
+// specified date and time of day. Times of day skipped when the clocks move
+// forward (e.g. 02:30 when the clocks move from 02:00 to 03:00) are moved
+// forward by the length of the gap, so that the run is not missed. Times of
+// day which occur twice when the clocks move back resolve to the first
+// occurrence, so that the run is not repeated.
+func wallClockTime(year int, month time.Month, day int, hour int, min int,
+	sec int, nsec int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+
+	// The offsets from UTC in effect a day either side of the specified time
+	// cover any transition on that day.
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	var earliest time.Time
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		candidate := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if sameWallClock(candidate, wall) &&
+			(earliest.IsZero() || candidate.Before(earliest)) {
+			earliest = candidate
+		}
+	}
+	if !earliest.IsZero() {
+		return earliest
+	}
+
+	// The time of day was skipped. The offset in effect before the clocks
+	// moved forward places the run after the gap.
+	return wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+}
+
+// Check if the instant shows the same date and time of day as the specified
+// wall clock time, expressed in UTC.
+func sameWallClock(t time.Time, wall time.Time) bool {
+	year, month, day := t.Date()
+	return year == wall.Year() && month == wall.Month() && day == wall.Day() &&
+		t.Hour() == wall.Hour() && t.Minute() == wall.Minute() &&
+		t.Second() == wall.Second() && t.Nanosecond() == wall.Nanosecond()
+}
+
 // Check if the specified weekday is one of the scheduled week days for the
 // task to run.
 func in(scheduleWeekdays []time.Weekday, weekday time.Weekday) bool {
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestWallClockTime(t *testing.T) {
+	loc, err := loadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Failed to load the timezone: %v\n", err)
+	}
+
+	tests := []struct {
+		name     string
+		actual   time.Time
+		expected string
+	}{
+		// The clocks move forward from 02:00 to 03:00 on 10 March 2024.
+		{"Gap",
+			wallClockTime(2024, time.March, 10, 2, 30, 0, 0, loc),
+			"2024-03-10T07:30:00Z"},
+		// The clocks move back from 02:00 to 01:00 on 3 November 2024.
+		{"Overlap",
+			wallClockTime(2024, time.November, 3, 1, 30, 0, 0, loc),
+			"2024-11-03T05:30:00Z"},
+		{"AfterTransition",
+			wallClockTime(2024, time.November, 3, 9, 0, 0, 0, loc),
+			"2024-11-03T14:00:00Z"},
+		{"Standard",
+			wallClockTime(2024, time.January, 15, 2, 0, 0, 0, loc),
+			"2024-01-15T07:00:00Z"},
+	}
+
+	for _, test := range tests {
+		if test.actual.UTC().Format(time.RFC3339) != test.expected {
+			t.Errorf("%s: expected %s, got %v\n", test.name, test.expected,
+				test.actual.UTC())
+		}
+	}
+}
+
+func TestNextRunAfter_LocalTimeAcrossDST(t *testing.T) {
+	// A nightly 02:00 task runs at 02:00 local time all year, and at 03:00 on
+	// the night the 02:00 hour is skipped.
+	loc, _ := loadLocation("America/New_York")
+	task := &db.Task{
+		Unit:     common.Days,
+		Interval: 1,
+		RunAt:    []time.Duration{2 * time.Hour},
+		Timezone: "America/New_York",
+	}
+
+	last := time.Date(2024, time.March, 8, 2, 0, 0, 0, loc)
+	expected := []string{"2024-03-09T02:00:00-05:00",
+		"2024-03-10T03:00:00-04:00", "2024-03-11T02:00:00-04:00"}
+	for _, want := range expected {
+		nextRun, ok := nextRunAfter(task, last, last, taskLocation(task))
+		if !ok || nextRun.Format(time.RFC3339) != want {
+			t.Errorf("Expected the next run at %s, got %v\n", want, nextRun)
+		}
+		last = nextRun
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	loc, err := loadLocation("")
+	if err != nil || loc != time.UTC {
+		t.Errorf("Expected UTC when no timezone is specified, got %v\n", loc)
+	}
+
+	_, err = loadLocation("Mars/Olympus_Mons")
+	if err != ErrInvalidTimezone {
+		t.Errorf("Expected an invalid timezone error, got %v\n", err)
+	}
+
+	if taskLocation(&db.Task{Timezone: "Mars/Olympus_Mons"}) != time.UTC {
+		t.Errorf("Expected tasks with an invalid timezone to use UTC\n")
+	}
+}
+
+func TestNextRunAfter_CrontabAcrossDST(t *testing.T) {
+	tests := []struct {
+		name     string
+		cronSpec string
+		last     string
+		expected []string
+	}{
+		// 01:30 occurs twice on the night the clocks move back, but the task
+		// only runs at the first occurrence.
+		{"repeated", "CRON_TZ=America/Los_Angeles 30 1 * * *",
+			"2027-11-06T01:30:00-07:00",
+			[]string{"2027-11-07T01:30:00-07:00", "2027-11-08T01:30:00-08:00"}},
+		// 02:30 is skipped on the night the clocks move forward, so the task
+		// runs after the gap instead.
+		{"skipped", "CRON_TZ=America/Los_Angeles 30 2 * * *",
+			"2027-03-13T02:30:00-08:00",
+			[]string{"2027-03-14T03:30:00-07:00", "2027-03-15T02:30:00-07:00"}},
+	}
+
+	for _, test := range tests {
+		task := &db.Task{
+			Unit:     common.Crontab,
+			CronSpec: test.cronSpec,
+		}
+		last, _ := time.Parse(time.RFC3339, test.last)
+		for _, want := range test.expected {
+			nextRun, ok := nextRunAfter(task, last, last, time.UTC)
+			expected, _ := time.Parse(time.RFC3339, want)
+			if !ok || !nextRun.Equal(expected) {
+				t.Errorf("%s: expected the next run at %s, got %v\n",
+					test.name, want, nextRun)
+			}
+			last = nextRun
+		}
+	}
+}
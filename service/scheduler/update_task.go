@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	pb "github.com/hpinc/krypton-scheduler/protos"
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+	"go.uber.org/zap"
+)
+
+var UpdateTaskHandlerFunc = updateTask
+
+// Update the schedule, message type and/or payload of the specified task in
+// place. A new schedule is validated in the same way as the schedule of a new
+// task, and the next run of the task is recomputed as per the new schedule.
+// Tasks that have already finished cannot be updated.
+func updateTask(taskID string, deviceID string,
+	request *pb.UpdateScheduledTaskRequest) (*db.Task, error) {
+	task, err := db.GetTaskByID(taskID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The scheduler reserves some message types for its own use.
+	if request.MessageType == common.CancelTaskMessageType {
+		return nil, ErrInvalidRequest
+	}
+
+	_, err = parseEndAt(request.EndAt)
+	if err != nil {
+		return nil, err
+	}
+	if request.MaxRuns < 0 {
+		return nil, ErrInvalidMaxRuns
+	}
+	_, err = parseJitter(request.Jitter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = changeTask(task, func(task *db.Task) error {
+		return applyTaskUpdate(task, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schedLogger.Info("Updated the task!",
+		zap.String("Task ID", taskID),
+		zap.String("Device ID", deviceID),
+		zap.Time("Next Run", task.NextRun),
+	)
+	return task, nil
+}
+
+// Apply the requested update to the task and store the updated task.
+func applyTaskUpdate(task *db.Task, request *pb.UpdateScheduledTaskRequest) error {
+	if isFinished(task) {
+		schedLogger.Error("Rejecting an update to a task that has already finished!",
+			zap.String("Task ID", task.TaskID.String()),
+			zap.String("Status", task.Status),
+		)
+		return ErrTaskFinished
+	}
+
+	if request.MessageType != "" {
+		task.MessageType = request.MessageType
+	}
+	if request.Payload != nil {
+		task.TaskDetails = request.Payload
+	}
+
+	// A change to the schedule of the task, its bounds or its jitter window
+	// reschedules the next run of the task. Bounds specified in the request
+	// replace those of the task, while bounds in a new schedule apply as they
+	// do for a new task.
+	var nextRun time.Time
+	if request.Schedule != "" || request.EndAt != "" || request.MaxRuns != 0 ||
+		request.Jitter != "" {
+		s := &ScheduledTask{
+			location:     taskLocation(task),
+			TaskInfo:     task,
+			ScheduleInfo: db.NewScheduledRun(task),
+		}
+		if request.EndAt != "" {
+			task.EndAt = time.Time{}
+		}
+		if request.MaxRuns != 0 {
+			task.MaxRuns = 0
+		}
+		if request.Schedule != "" {
+			s.resetSchedule()
+			s.ParseSchedule(request.Schedule)
+		}
+		err := s.ScheduleBounds(request.EndAt, request.MaxRuns).
+			Jitter(request.Jitter).
+			validate()
+		if err != nil {
+			schedLogger.Error("Failed to parse the new schedule for the task!",
+				zap.String("Task ID", task.TaskID.String()),
+				zap.String("Schedule", request.Schedule),
+				zap.Error(err),
+			)
+			var scheduleErr *ScheduleError
+			if errors.As(err, &scheduleErr) {
+				return scheduleErr
+			}
+			return ErrInvalidRequest
+		}
+		nextRun = jitteredRunTime(task,
+			firstRunTime(task, time.Now(), s.location))
+	}
+
+	return db.UpdateTaskDefinition(task, nextRun)
+}
+
+// Clear the schedule of the task, so that a new schedule can be parsed. The
+// end time, maximum number of runs and jitter window of the task may also be
+// specified outside of its schedule, so they are kept.
+func (s *ScheduledTask) resetSchedule() {
+	s.TaskInfo.Unit = 0
+	s.TaskInfo.Interval = 0
+	s.TaskInfo.Duration = 0
+	s.TaskInfo.RunAt = nil
+	s.TaskInfo.ScheduledWeekdays = nil
+	s.TaskInfo.ScheduledDaysOfTheMonth = nil
+	s.TaskInfo.StartAt = time.Time{}
+	s.TaskInfo.StartImmediately = false
+	s.TaskInfo.CronSpec = ""
+	s.TaskInfo.CronWithSeconds = false
+	s.TaskInfo.RRuleSpec = ""
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpinc/krypton-scheduler/service/common"
+	"github.com/hpinc/krypton-scheduler/service/db"
+)
+
+func TestResetSchedule(t *testing.T) {
+	task := &db.Task{
+		Unit:            common.Crontab,
+		CronSpec:        "0 9 * * *",
+		StartAt:         time.Now(),
+		RunAt:           []time.Duration{time.Hour},
+		CronWithSeconds: true,
+	}
+	s := &ScheduledTask{location: time.UTC, TaskInfo: task,
+		ScheduleInfo: db.NewScheduledRun(task)}
+
+	s.resetSchedule()
+	err := s.ParseSchedule("every 2 hours").validate()
+	if err != nil {
+		t.Errorf("Failed to parse the new schedule with error %v\n", err)
+		return
+	}
+
+	if task.Unit != common.Hours || task.Interval != 2 ||
+		task.CronSpec != "" || len(task.RunAt) != 0 {
+		t.Errorf("Unexpected schedule after update: %+v\n", task)
+	}
+}
+
+func TestResetScheduleKeepsBounds(t *testing.T) {
+	endAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	task := &db.Task{
+		Unit:         common.Hours,
+		Interval:     1,
+		EndAt:        endAt,
+		MaxRuns:      10,
+		JitterWindow: 5 * time.Minute,
+	}
+	s := &ScheduledTask{location: time.UTC, TaskInfo: task,
+		ScheduleInfo: db.NewScheduledRun(task)}
+
+	s.resetSchedule()
+	err := s.ParseSchedule("every 2 hours").validate()
+	if err != nil {
+		t.Errorf("Failed to parse the new schedule with error %v\n", err)
+		return
+	}
+
+	if !task.EndAt.Equal(endAt) || task.MaxRuns != 10 ||
+		task.JitterWindow != 5*time.Minute {
+		t.Errorf("Expected the bounds of the task to be kept: %+v\n", task)
+	}
+}